@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/json_rpc"
+)
+
+const (
+	exit_ok      = 0
+	exit_failed  = 1
+	exit_usage   = 2
+	stdin_params = "-"
+)
+
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ", ")
+}
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+type batchCall struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type errorModule struct {
+	Module int            `json:"module"`
+	Name   string         `json:"name"`
+	Errors map[int]string `json:"errors"`
+}
+
+func loadErrorModules(path string) custom_error.CustomError {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to read error modules file '%v'. Error: %v", path, err)
+	}
+	modules := []errorModule{}
+	err = json.Unmarshal(data, &modules)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to parse error modules file '%v'. Error: %v", path, err)
+	}
+	for i := range modules {
+		cErr := json_rpc.RegisterErrorModule(modules[i].Module, modules[i].Name, modules[i].Errors)
+		if cErr != nil {
+			return custom_error.WrapErrorf(cErr, "Failed to register error module '%v'.", modules[i].Name)
+		}
+	}
+	return nil
+}
+
+func parseHeaders(values []string) (http.Header, custom_error.CustomError) {
+	headers := http.Header{}
+	for _, v := range values {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 {
+			return nil, custom_error.MakeErrorf("Invalid header '%v'. Expected 'Name: value'.", v)
+		}
+		headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return headers, nil
+}
+
+func parseCookies(values []string) ([]*http.Cookie, custom_error.CustomError) {
+	cookies := []*http.Cookie{}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, custom_error.MakeErrorf("Invalid cookie '%v'. Expected 'name=value'.", v)
+		}
+		cookies = append(cookies, &http.Cookie{
+			Name:  strings.TrimSpace(parts[0]),
+			Value: strings.TrimSpace(parts[1]),
+		})
+	}
+	return cookies, nil
+}
+
+func readParams(params string, stdin io.Reader) (json.RawMessage, custom_error.CustomError) {
+	if params != stdin_params {
+		if len(params) <= 0 {
+			return nil, nil
+		}
+		if !json.Valid([]byte(params)) {
+			return nil, custom_error.MakeErrorf("Params are not valid JSON: %v", params)
+		}
+		return json.RawMessage(params), nil
+	}
+	data, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to read params from stdin. Error: %v", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) <= 0 {
+		return nil, nil
+	}
+	if !json.Valid(data) {
+		return nil, custom_error.MakeErrorf("Params from stdin are not valid JSON.")
+	}
+	return data, nil
+}
+
+func readBatch(path string, stdin io.Reader) ([]batchCall, custom_error.CustomError) {
+	r := stdin
+	if path != stdin_params {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, custom_error.MakeErrorf("Failed to open batch file '%v'. Error: %v", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	calls := []batchCall{}
+	dec := json.NewDecoder(r)
+	for {
+		call := batchCall{}
+		err := dec.Decode(&call)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, custom_error.MakeErrorf("Failed to parse batch entry %v. Error: %v", len(calls)+1, err)
+		}
+		if len(call.Method) <= 0 {
+			return nil, custom_error.MakeErrorf("Batch entry %v has no method.", len(calls)+1)
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func prettyJSON(data []byte) string {
+	var buf bytes.Buffer
+	err := json.Indent(&buf, data, "", "  ")
+	if err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+func printError(w io.Writer, err custom_error.CustomError) {
+	remoteErr, ok := err.(json_rpc.RemoteError)
+	if !ok {
+		fmt.Fprintf(w, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "error: %v (module: %v, code: %v, raw code: %v)\n", json_rpc.DescribeErrorCode(remoteErr.GetCode()), remoteErr.GetModule(), remoteErr.GetErrorCode(), remoteErr.GetCode())
+	fmt.Fprintf(w, "http status: %v\n", remoteErr.GetStatus())
+	fmt.Fprintf(w, "message: %v\n", remoteErr.GetMessage())
+	data := remoteErr.GetRawData()
+	if len(data) > 0 {
+		fmt.Fprintf(w, "data: %v\n", prettyJSON(data))
+	}
+}
+
+func call(client json_rpc.Client, url string, method string, params json.RawMessage, headers http.Header, cookies []*http.Cookie) bool {
+	args := json_rpc.RPCArguments{
+		Headers: http.Header{},
+		Cookies: cookies,
+	}
+	for k, vs := range headers {
+		args.Headers[k] = append([]string{}, vs...)
+	}
+	if len(params) > 0 {
+		args.Data = params
+	}
+	response, err := client.Call(url, method, args, func() interface{} {
+		return &json.RawMessage{}
+	})
+	if err != nil {
+		printError(os.Stderr, err)
+		return false
+	}
+	result, ok := response.Result.(*json.RawMessage)
+	if !ok || result == nil || len(*result) <= 0 {
+		fmt.Println("null")
+		return true
+	}
+	fmt.Println(prettyJSON(*result))
+	return true
+}
+
+func run() int {
+	var headerValues, cookieValues multiFlag
+	url := flag.String("url", "", "JSON-RPC endpoint URL.")
+	method := flag.String("method", "", "Method to call.")
+	params := flag.String("params", "", "Params as JSON. Use '-' to read from stdin.")
+	batch := flag.String("batch", "", "File with a stream of {\"method\": ..., \"params\": ...} objects. Use '-' for stdin.")
+	errorModules := flag.String("errors", "", "JSON file with error tables: [{\"module\": 1, \"name\": \"billing\", \"errors\": {\"1\": \"INSUFFICIENT_FUNDS\"}}].")
+	timeout := flag.Duration("timeout", 30*time.Second, "Request timeout.")
+	flag.Var(&headerValues, "H", "Request header 'Name: value'. May be repeated.")
+	flag.Var(&cookieValues, "b", "Request cookie 'name=value'. May be repeated.")
+	flag.Parse()
+
+	if len(*url) <= 0 || (len(*method) <= 0 && len(*batch) <= 0) {
+		flag.Usage()
+		return exit_usage
+	}
+	if len(*errorModules) > 0 {
+		cErr := loadErrorModules(*errorModules)
+		if cErr != nil {
+			fmt.Fprintln(os.Stderr, cErr.GetError())
+			return exit_usage
+		}
+	}
+	headers, cErr := parseHeaders(headerValues)
+	if cErr != nil {
+		fmt.Fprintln(os.Stderr, cErr.GetError())
+		return exit_usage
+	}
+	cookies, cErr := parseCookies(cookieValues)
+	if cErr != nil {
+		fmt.Fprintln(os.Stderr, cErr.GetError())
+		return exit_usage
+	}
+
+	calls := []batchCall{}
+	if len(*batch) > 0 {
+		calls, cErr = readBatch(*batch, os.Stdin)
+	} else {
+		var data json.RawMessage
+		data, cErr = readParams(*params, os.Stdin)
+		calls = append(calls, batchCall{
+			Method: *method,
+			Params: data,
+		})
+	}
+	if cErr != nil {
+		fmt.Fprintln(os.Stderr, cErr.GetError())
+		return exit_usage
+	}
+
+	client := json_rpc.NewClient(&http.Client{
+		Timeout: *timeout,
+	})
+	exitCode := exit_ok
+	for i := range calls {
+		if len(calls) > 1 {
+			fmt.Printf("# %v\n", calls[i].Method)
+		}
+		if !call(client, *url, calls[i].Method, calls[i].Params, headers, cookies) {
+			exitCode = exit_failed
+		}
+	}
+	return exitCode
+}
+
+func main() {
+	os.Exit(run())
+}
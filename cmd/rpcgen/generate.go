@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const generatedTemplate = `// Code generated by rpcgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+
+	"github.com/coldze/primitives/json_rpc"
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+const (
+{{- range .Methods }}
+	{{ $.Name }}Method{{ .Name }} = "{{ .RPCName }}"
+{{- end }}
+)
+
+func Register{{ .Name }}Handlers(handlers map[string]json_rpc.HandlingInfo, impl {{ .Name }}) map[string]json_rpc.HandlingInfo {
+	if handlers == nil {
+		handlers = map[string]json_rpc.HandlingInfo{}
+	}
+{{- range .Methods }}
+	handlers[{{ $.Name }}Method{{ .Name }}] = json_rpc.HandlingInfo{
+		NewParams: func() interface{} {
+			return &{{ .Request }}{}
+		},
+		Handle: func(ctx context.Context, request *json_rpc.RequestInfo) (*json_rpc.ResponseInfo, json_rpc.ServerError) {
+			params, ok := request.Data.(*{{ .Request }})
+			if !ok || params == nil {
+				return nil, json_rpc.MakeInvalidParamsError({{ $.Name }}Method{{ .Name }}, request.Data)
+			}
+			response, err := impl.{{ .Name }}(ctx, params)
+			if err != nil {
+				return nil, json_rpc.ToServerError(err)
+			}
+			return &json_rpc.ResponseInfo{
+				Data: response,
+			}, nil
+		},
+	}
+{{- end }}
+	return handlers
+}
+
+var _ {{ .Name }} = (*{{ .Name }}Client)(nil)
+
+type {{ .Name }}Client struct {
+	client json_rpc.CancellableClient
+	url    string
+}
+{{ range .Methods }}
+func (c *{{ $.Name }}Client) {{ .Name }}(ctx context.Context, request *{{ .Request }}) (*{{ .Response }}, error) {
+	response, err := c.client.CallContext(ctx, c.url, {{ $.Name }}Method{{ .Name }}, json_rpc.RPCArguments{Data: request}, func() interface{} {
+		return &{{ .Response }}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, _ := response.Result.(*{{ .Response }})
+	return result, nil
+}
+{{ end }}
+func New{{ .Name }}Client(client json_rpc.CancellableClient, url string) *{{ .Name }}Client {
+	return &{{ .Name }}Client{
+		client: client,
+		url:    url,
+	}
+}
+`
+
+var generated = template.Must(template.New("rpcgen").Parse(generatedTemplate))
+
+func generate(info *interfaceInfo) ([]byte, custom_error.CustomError) {
+	var buf bytes.Buffer
+	err := generated.Execute(&buf, info)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to execute template. Error: %v", err)
+	}
+	res, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to format generated code. Error: %v\n%s", err, buf.Bytes())
+	}
+	return res, nil
+}
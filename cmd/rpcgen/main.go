@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+func defaultNamespace(name string) string {
+	runes := []rune(name)
+	if len(runes) <= 0 {
+		return name
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func defaultOutput(source string, name string) string {
+	dir := filepath.Dir(source)
+	return filepath.Join(dir, strings.ToLower(name)+"_rpc.gen.go")
+}
+
+func main() {
+	source := flag.String("source", os.Getenv("GOFILE"), "Go file that declares the interface.")
+	name := flag.String("interface", "", "Name of the interface to generate server adapter and client for.")
+	namespace := flag.String("namespace", "", "Method namespace. Defaults to the interface name with lower-cased first letter.")
+	output := flag.String("output", "", "Output file. Defaults to <interface>_rpc.gen.go next to the source.")
+	flag.Parse()
+
+	if len(*source) <= 0 || len(*name) <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if len(*namespace) <= 0 {
+		*namespace = defaultNamespace(*name)
+	}
+	if len(*output) <= 0 {
+		*output = defaultOutput(*source, *name)
+	}
+
+	info, cErr := parseInterface(*source, *name, *namespace)
+	if cErr != nil {
+		log.Fatalf("Failed to read interface. Error: %v", cErr)
+	}
+	data, cErr := generate(info)
+	if cErr != nil {
+		log.Fatalf("Failed to generate code. Error: %v", cErr)
+	}
+	err := ioutil.WriteFile(*output, data, 0644)
+	if err != nil {
+		log.Fatalf("Failed to write '%v'. Error: %v", *output, err)
+	}
+}
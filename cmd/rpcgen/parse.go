@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strconv"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	json_rpc_import = "github.com/coldze/primitives/json_rpc"
+)
+
+type methodInfo struct {
+	Name     string
+	RPCName  string
+	Request  string
+	Response string
+}
+
+type interfaceInfo struct {
+	Package   string
+	Name      string
+	Namespace string
+	Imports   []string
+	Methods   []methodInfo
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) (string, custom_error.CustomError) {
+	var buf bytes.Buffer
+	err := printer.Fprint(&buf, fset, expr)
+	if err != nil {
+		return "", custom_error.MakeErrorf("Failed to print expression. Error: %v", err)
+	}
+	return buf.String(), nil
+}
+
+func collectPackages(expr ast.Expr, used map[string]struct{}) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if ok {
+			used[ident.Name] = struct{}{}
+		}
+		return false
+	})
+}
+
+func isContext(fset *token.FileSet, expr ast.Expr) bool {
+	s, err := exprString(fset, expr)
+	return err == nil && s == "context.Context"
+}
+
+func isError(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	res := []ast.Expr{}
+	if list == nil {
+		return res
+	}
+	for _, field := range list.List {
+		count := len(field.Names)
+		if count <= 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			res = append(res, field.Type)
+		}
+	}
+	return res
+}
+
+func parseMethod(fset *token.FileSet, namespace string, field *ast.Field) (methodInfo, map[string]struct{}, custom_error.CustomError) {
+	name := field.Names[0].Name
+	fn, ok := field.Type.(*ast.FuncType)
+	if !ok {
+		return methodInfo{}, nil, custom_error.MakeErrorf("Method '%v' is not a function.", name)
+	}
+	params := fieldTypes(fn.Params)
+	results := fieldTypes(fn.Results)
+	if len(params) != 2 || !isContext(fset, params[0]) {
+		return methodInfo{}, nil, custom_error.MakeErrorf("Method '%v' must accept (context.Context, *Request).", name)
+	}
+	if len(results) != 2 || !isError(results[1]) {
+		return methodInfo{}, nil, custom_error.MakeErrorf("Method '%v' must return (*Response, error).", name)
+	}
+	reqPtr, ok := params[1].(*ast.StarExpr)
+	if !ok {
+		return methodInfo{}, nil, custom_error.MakeErrorf("Method '%v' request must be a pointer.", name)
+	}
+	respPtr, ok := results[0].(*ast.StarExpr)
+	if !ok {
+		return methodInfo{}, nil, custom_error.MakeErrorf("Method '%v' response must be a pointer.", name)
+	}
+	used := map[string]struct{}{}
+	collectPackages(reqPtr.X, used)
+	collectPackages(respPtr.X, used)
+	req, cErr := exprString(fset, reqPtr.X)
+	if cErr != nil {
+		return methodInfo{}, nil, custom_error.WrapErrorf(cErr, "Failed to read request type of '%v'.", name)
+	}
+	resp, cErr := exprString(fset, respPtr.X)
+	if cErr != nil {
+		return methodInfo{}, nil, custom_error.WrapErrorf(cErr, "Failed to read response type of '%v'.", name)
+	}
+	return methodInfo{
+		Name:     name,
+		RPCName:  namespace + "." + name,
+		Request:  req,
+		Response: resp,
+	}, used, nil
+}
+
+func importName(spec *ast.ImportSpec) (string, string) {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	if spec.Name != nil {
+		return spec.Name.Name, path
+	}
+	name := path
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			name = path[i+1:]
+			break
+		}
+	}
+	return name, path
+}
+
+func parseInterface(path string, name string, namespace string) (*interfaceInfo, custom_error.CustomError) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to parse '%v'. Error: %v", path, err)
+	}
+	var iface *ast.InterfaceType
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return iface == nil
+		}
+		iface, _ = spec.Type.(*ast.InterfaceType)
+		return false
+	})
+	if iface == nil {
+		return nil, custom_error.MakeErrorf("Interface '%v' not found in '%v'.", name, path)
+	}
+	info := &interfaceInfo{
+		Package:   file.Name.Name,
+		Name:      name,
+		Namespace: namespace,
+	}
+	used := map[string]struct{}{}
+	for _, field := range iface.Methods.List {
+		if len(field.Names) <= 0 {
+			return nil, custom_error.MakeErrorf("Embedded interfaces are not supported. Interface: '%v'.", name)
+		}
+		method, methodUsed, cErr := parseMethod(fset, namespace, field)
+		if cErr != nil {
+			return nil, custom_error.WrapErrorf(cErr, "Failed to parse interface '%v'.", name)
+		}
+		for k := range methodUsed {
+			used[k] = struct{}{}
+		}
+		info.Methods = append(info.Methods, method)
+	}
+	for _, spec := range file.Imports {
+		alias, importPath := importName(spec)
+		_, ok := used[alias]
+		if !ok || importPath == json_rpc_import {
+			continue
+		}
+		info.Imports = append(info.Imports, alias+" "+strconv.Quote(importPath))
+	}
+	return info, nil
+}
@@ -2,4 +2,8 @@ module github.com/coldze/primitives
 
 go 1.13
 
-require github.com/google/uuid v1.1.1
+require (
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/google/uuid v1.1.1
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+)
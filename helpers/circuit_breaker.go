@@ -0,0 +1,193 @@
+package helpers
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	ErrorTypeCircuitOpen custom_error.ErrorType = 0x0b7ea4e7
+
+	default_open_timeout       = 10 * time.Second
+	default_half_open_requests = 1
+	default_rate_window        = 10 * time.Second
+	default_consecutive_fails  = 5
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type CircuitBreakerConfig struct {
+	ConsecutiveFailures int
+	ErrorRate           float64
+	MinRequests         int
+	Window              time.Duration
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+}
+
+type CircuitBreaker interface {
+	Allow() custom_error.CustomError
+	Report(err error)
+	Execute(call func() error) error
+	GetState() CircuitState
+}
+
+type circuitBreaker struct {
+	cfg         CircuitBreakerConfig
+	lock        sync.Mutex
+	state       CircuitState
+	consecutive int
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int
+	now         func() time.Time
+}
+
+func (c *circuitBreaker) currentState(now time.Time) CircuitState {
+	if c.state == CircuitOpen && now.Sub(c.openedAt) >= c.cfg.OpenTimeout {
+		c.state = CircuitHalfOpen
+		c.probes = 0
+	}
+	return c.state
+}
+
+func (c *circuitBreaker) GetState() CircuitState {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.currentState(c.now())
+}
+
+func (c *circuitBreaker) Allow() custom_error.CustomError {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.now()
+	switch c.currentState(now) {
+	case CircuitOpen:
+		return custom_error.MakeTypedErrorf(ErrorTypeCircuitOpen, "Circuit breaker is open. Retry after: %v", c.openedAt.Add(c.cfg.OpenTimeout).Sub(now))
+	case CircuitHalfOpen:
+		if c.probes >= c.cfg.HalfOpenRequests {
+			return custom_error.MakeTypedErrorf(ErrorTypeCircuitOpen, "Circuit breaker is half-open. Probe limit reached: %v", c.cfg.HalfOpenRequests)
+		}
+		c.probes++
+	}
+	return nil
+}
+
+func (c *circuitBreaker) trip(now time.Time) {
+	c.state = CircuitOpen
+	c.openedAt = now
+	c.resetCounters(now)
+}
+
+func (c *circuitBreaker) resetCounters(now time.Time) {
+	c.consecutive = 0
+	c.requests = 0
+	c.failures = 0
+	c.windowStart = now
+}
+
+func (c *circuitBreaker) shouldTrip() bool {
+	if c.cfg.ConsecutiveFailures > 0 && c.consecutive >= c.cfg.ConsecutiveFailures {
+		return true
+	}
+	if c.cfg.ErrorRate <= 0 || c.requests < c.cfg.MinRequests || c.requests <= 0 {
+		return false
+	}
+	return float64(c.failures)/float64(c.requests) >= c.cfg.ErrorRate
+}
+
+func (c *circuitBreaker) Report(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.now()
+	switch c.currentState(now) {
+	case CircuitOpen:
+		return
+	case CircuitHalfOpen:
+		if err != nil {
+			c.trip(now)
+			return
+		}
+		c.state = CircuitClosed
+		c.resetCounters(now)
+		return
+	}
+	if now.Sub(c.windowStart) >= c.cfg.Window {
+		c.requests = 0
+		c.failures = 0
+		c.windowStart = now
+	}
+	c.requests++
+	if err == nil {
+		c.consecutive = 0
+		return
+	}
+	c.failures++
+	c.consecutive++
+	if c.shouldTrip() {
+		c.trip(now)
+	}
+}
+
+func (c *circuitBreaker) Execute(call func() error) error {
+	cErr := c.Allow()
+	if cErr != nil {
+		return cErr
+	}
+	err := call()
+	c.Report(err)
+	return err
+}
+
+func IsCircuitOpenError(err error) bool {
+	var cErr custom_error.CustomError
+	if !errors.As(err, &cErr) {
+		return false
+	}
+	return cErr.GetType() == ErrorTypeCircuitOpen
+}
+
+func NewCircuitBreaker(cfg CircuitBreakerConfig) CircuitBreaker {
+	if cfg.ConsecutiveFailures <= 0 && cfg.ErrorRate <= 0 {
+		cfg.ConsecutiveFailures = default_consecutive_fails
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = default_open_timeout
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = default_half_open_requests
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = default_rate_window
+	}
+	now := time.Now
+	return &circuitBreaker{
+		cfg:         cfg,
+		state:       CircuitClosed,
+		windowStart: now(),
+		now:         now,
+	}
+}
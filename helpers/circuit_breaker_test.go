@@ -0,0 +1,178 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestCircuitBreaker(cfg CircuitBreakerConfig) (*circuitBreaker, *testClock) {
+	clock := &testClock{
+		now: time.Unix(1000, 0),
+	}
+	breaker := NewCircuitBreaker(cfg).(*circuitBreaker)
+	breaker.now = clock.Now
+	breaker.windowStart = clock.Now()
+	return breaker, clock
+}
+
+var errTestFailure = errors.New("failure")
+
+type breakerStep struct {
+	advance  time.Duration
+	allow    bool
+	report   error
+	expected CircuitState
+}
+
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	cases := []struct {
+		name  string
+		cfg   CircuitBreakerConfig
+		steps []breakerStep
+	}{
+		{
+			name: "consecutive failures trip the breaker",
+			cfg: CircuitBreakerConfig{
+				ConsecutiveFailures: 2,
+				OpenTimeout:         time.Second,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitClosed},
+				{allow: true, report: errTestFailure, expected: CircuitOpen},
+				{allow: false, expected: CircuitOpen},
+			},
+		},
+		{
+			name: "success resets consecutive failures",
+			cfg: CircuitBreakerConfig{
+				ConsecutiveFailures: 2,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitClosed},
+				{allow: true, report: nil, expected: CircuitClosed},
+				{allow: true, report: errTestFailure, expected: CircuitClosed},
+			},
+		},
+		{
+			name: "open breaker becomes half-open after timeout and closes on success",
+			cfg: CircuitBreakerConfig{
+				ConsecutiveFailures: 1,
+				OpenTimeout:         time.Second,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitOpen},
+				{advance: 500 * time.Millisecond, allow: false, expected: CircuitOpen},
+				{advance: 500 * time.Millisecond, allow: true, report: nil, expected: CircuitClosed},
+				{allow: true, report: nil, expected: CircuitClosed},
+			},
+		},
+		{
+			name: "failed half-open probe reopens the breaker",
+			cfg: CircuitBreakerConfig{
+				ConsecutiveFailures: 1,
+				OpenTimeout:         time.Second,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitOpen},
+				{advance: time.Second, allow: true, report: errTestFailure, expected: CircuitOpen},
+				{allow: false, expected: CircuitOpen},
+			},
+		},
+		{
+			name: "error rate trips after minimum requests",
+			cfg: CircuitBreakerConfig{
+				ErrorRate:   0.5,
+				MinRequests: 4,
+				Window:      time.Minute,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitClosed},
+				{allow: true, report: nil, expected: CircuitClosed},
+				{allow: true, report: nil, expected: CircuitClosed},
+				{allow: true, report: errTestFailure, expected: CircuitOpen},
+			},
+		},
+		{
+			name: "error rate window resets counters",
+			cfg: CircuitBreakerConfig{
+				ErrorRate:   0.5,
+				MinRequests: 2,
+				Window:      time.Second,
+			},
+			steps: []breakerStep{
+				{allow: true, report: errTestFailure, expected: CircuitClosed},
+				{advance: time.Second, allow: true, report: nil, expected: CircuitClosed},
+				{allow: true, report: nil, expected: CircuitClosed},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			breaker, clock := newTestCircuitBreaker(c.cfg)
+			for i, step := range c.steps {
+				clock.Advance(step.advance)
+				err := breaker.Allow()
+				if (err == nil) != step.allow {
+					t.Fatalf("Step %v: expected allow=%v, got error: %v", i, step.allow, err)
+				}
+				if err != nil && !IsCircuitOpenError(err) {
+					t.Fatalf("Step %v: expected circuit open error, got: %v", i, err)
+				}
+				if err == nil {
+					breaker.Report(step.report)
+				}
+				state := breaker.GetState()
+				if state != step.expected {
+					t.Fatalf("Step %v: expected state %v, got %v", i, step.expected, state)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeLimit(t *testing.T) {
+	cases := []struct {
+		name    string
+		probes  int
+		allowed int
+	}{
+		{name: "default single probe", probes: 0, allowed: 1},
+		{name: "three probes", probes: 3, allowed: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			breaker, clock := newTestCircuitBreaker(CircuitBreakerConfig{
+				ConsecutiveFailures: 1,
+				OpenTimeout:         time.Second,
+				HalfOpenRequests:    c.probes,
+			})
+			breaker.Report(errTestFailure)
+			clock.Advance(time.Second)
+			allowed := 0
+			for i := 0; i < c.allowed+2; i++ {
+				if breaker.Allow() == nil {
+					allowed++
+				}
+			}
+			if allowed != c.allowed {
+				t.Fatalf("Expected %v half-open probes, got %v.", c.allowed, allowed)
+			}
+			if breaker.GetState() != CircuitHalfOpen {
+				t.Fatalf("Expected half-open state, got %v.", breaker.GetState())
+			}
+		})
+	}
+}
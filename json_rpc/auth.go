@@ -0,0 +1,164 @@
+package json_rpc
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	bearer_prefix          = "Bearer "
+	anonymous_principal_id = "anonymous"
+)
+
+type Principal interface {
+	GetID() string
+	GetScopes() []string
+	HasScope(scope string) bool
+}
+
+type Authenticator func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError)
+type BearerTokenValidator func(ctx context.Context, token string) (Principal, ServerError)
+type PeerCertificateValidator func(ctx context.Context, chain []*x509.Certificate) (Principal, ServerError)
+
+type principal struct {
+	id     string
+	scopes []string
+}
+
+func (p *principal) GetID() string {
+	return p.id
+}
+
+func (p *principal) GetScopes() []string {
+	return p.scopes
+}
+
+func (p *principal) HasScope(scope string) bool {
+	for i := range p.scopes {
+		if p.scopes[i] == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func NewPrincipal(id string, scopes []string) Principal {
+	return &principal{
+		id:     id,
+		scopes: append([]string{}, scopes...),
+	}
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func GetPrincipal(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok && p != nil
+}
+
+func principalScope(ctx context.Context, method string, key string) string {
+	id := anonymous_principal_id
+	p, ok := GetPrincipal(ctx)
+	if ok {
+		id = p.GetID()
+	}
+	return id + "\n" + method + "\n" + key
+}
+
+func MakeUnauthorizedError(message string, err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 7, http.StatusUnauthorized, message, err)
+}
+
+func MakeForbiddenError(message string, err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 8, http.StatusForbidden, message, err)
+}
+
+func NewBearerTokenAuthenticator(validate BearerTokenValidator) Authenticator {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError) {
+		header := rawHttpRequest.Header.Get("Authorization")
+		if len(header) <= 0 {
+			return nil, nil
+		}
+		if !strings.HasPrefix(header, bearer_prefix) {
+			return nil, MakeUnauthorizedError("Unsupported authorization scheme.", nil)
+		}
+		token := strings.TrimSpace(header[len(bearer_prefix):])
+		if len(token) <= 0 {
+			return nil, MakeUnauthorizedError("Empty bearer token.", nil)
+		}
+		return validate(ctx, token)
+	}
+}
+
+func NewPeerCertificateAuthenticator(validate PeerCertificateValidator) Authenticator {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError) {
+		if rawHttpRequest.TLS == nil || len(rawHttpRequest.TLS.PeerCertificates) <= 0 {
+			return nil, nil
+		}
+		chain := rawHttpRequest.TLS.PeerCertificates
+		if len(rawHttpRequest.TLS.VerifiedChains) > 0 {
+			chain = rawHttpRequest.TLS.VerifiedChains[0]
+		}
+		return validate(ctx, chain)
+	}
+}
+
+func NewCompositeAuthenticator(authenticators []Authenticator) Authenticator {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError) {
+		for i := range authenticators {
+			p, err := authenticators[i](ctx, request, rawHttpRequest)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				return p, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func WithAuthenticator(handlers map[string]HandlingInfo, authenticate Authenticator) map[string]HandlingInfo {
+	res := make(map[string]HandlingInfo, len(handlers))
+	for k, v := range handlers {
+		if v.Authenticate == nil {
+			v.Authenticate = authenticate
+		}
+		res[k] = v
+	}
+	return res
+}
+
+func authorize(ctx context.Context, handler HandlingInfo, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+	if handler.Authenticate == nil && len(handler.RequiredScopes) <= 0 {
+		return ctx, nil
+	}
+	var p Principal
+	if handler.Authenticate != nil {
+		var err ServerError
+		p, err = handler.Authenticate(ctx, request, rawHttpRequest)
+		if err != nil {
+			return ctx, err
+		}
+	}
+	if p == nil {
+		return ctx, MakeUnauthorizedError("Authentication required.", errors.New("Method: "+request.Method))
+	}
+	ctx = WithPrincipal(ctx, p)
+	for i := range handler.RequiredScopes {
+		if !p.HasScope(handler.RequiredScopes[i]) {
+			return ctx, MakeForbiddenError("Insufficient permissions.", errors.New("Missing scope: "+handler.RequiredScopes[i]))
+		}
+	}
+	return ctx, nil
+}
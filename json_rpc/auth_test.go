@@ -0,0 +1,127 @@
+package json_rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newAuthTestHandlers(composed *int32, principalSeen *int32) RpcHandlers {
+	authenticate := NewBearerTokenAuthenticator(func(ctx context.Context, token string) (Principal, ServerError) {
+		switch token {
+		case "reader":
+			return NewPrincipal("reader", []string{"read"}), nil
+		case "writer":
+			return NewPrincipal("writer", []string{"read", "write"}), nil
+		}
+		return nil, MakeUnauthorizedError("Unknown token.", nil)
+	})
+	return NewDefaultRpcHandlers(map[string]HandlingInfo{
+		"write": {
+			Authenticate:   authenticate,
+			RequiredScopes: []string{"write"},
+			NewParams: func() interface{} {
+				return &struct{}{}
+			},
+			ComposeContext: func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+				atomic.AddInt32(composed, 1)
+				_, ok := GetPrincipal(ctx)
+				if ok {
+					atomic.AddInt32(principalSeen, 1)
+				}
+				return ctx, nil
+			},
+			Handle: func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+				p, _ := GetPrincipal(ctx)
+				return &ResponseInfo{
+					Data: p.GetID(),
+				}, nil
+			},
+		},
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	var composed, principalSeen int32
+	server := httptest.NewServer(http.HandlerFunc(CreateJSONRpcHandler(newAuthTestHandlers(&composed, &principalSeen))))
+	defer server.Close()
+
+	cases := []struct {
+		name          string
+		authorization string
+		status        int
+		composed      int32
+	}{
+		{name: "missing principal", authorization: "", status: http.StatusUnauthorized, composed: 0},
+		{name: "invalid token", authorization: "Bearer unknown", status: http.StatusUnauthorized, composed: 0},
+		{name: "unsupported scheme", authorization: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized, composed: 0},
+		{name: "missing scope", authorization: "Bearer reader", status: http.StatusForbidden, composed: 0},
+		{name: "authorized", authorization: "Bearer writer", status: http.StatusOK, composed: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			atomic.StoreInt32(&composed, 0)
+			atomic.StoreInt32(&principalSeen, 0)
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":"1","method":"write"}`))
+			if err != nil {
+				t.Fatalf("Failed to build request. Error: %v", err)
+			}
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			if len(c.authorization) > 0 {
+				req.Header.Set("Authorization", c.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed. Error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != c.status {
+				t.Fatalf("Expected status %v, got %v.", c.status, resp.StatusCode)
+			}
+			if composed != c.composed {
+				t.Fatalf("Expected ComposeContext to run %v times, ran %v times.", c.composed, composed)
+			}
+			if principalSeen != composed {
+				t.Fatalf("ComposeContext ran before authentication. Calls: %v. With principal: %v", composed, principalSeen)
+			}
+		})
+	}
+}
+
+func TestAuthorizeWithoutAuthenticator(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler HandlingInfo
+		status  int
+	}{
+		{name: "public method", handler: HandlingInfo{}, status: 0},
+		{name: "scopes without principal", handler: HandlingInfo{RequiredScopes: []string{"read"}}, status: http.StatusUnauthorized},
+		{
+			name: "authenticator returns no principal",
+			handler: HandlingInfo{
+				Authenticate: func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError) {
+					return nil, nil
+				},
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rawHttpRequest := httptest.NewRequest(http.MethodPost, "/", nil)
+			_, err := authorize(context.Background(), c.handler, &RequestBase{Method: "method"}, rawHttpRequest)
+			if c.status == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err.GetMessage())
+				}
+				return
+			}
+			if err == nil || err.GetStatus() != c.status {
+				t.Fatalf("Expected status %v, got: %v", c.status, err)
+			}
+		})
+	}
+}
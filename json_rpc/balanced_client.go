@@ -0,0 +1,45 @@
+package json_rpc
+
+import (
+	"github.com/coldze/primitives/custom_error"
+)
+
+type BalancedClient interface {
+	Call(method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError)
+	CallWithKey(key string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError)
+	Close()
+}
+
+type balancedClient struct {
+	client Client
+	pool   EndpointPool
+}
+
+func (c *balancedClient) Call(method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	return c.CallWithKey("", method, args, expectedResult)
+}
+
+func (c *balancedClient) CallWithKey(key string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	e, err := c.pool.Acquire(key)
+	if err != nil {
+		return nil, custom_error.WrapErrorf(err, "Failed to pick endpoint for method '%v'.", method)
+	}
+	response, callErr := c.client.Call(e.GetURL(), method, args, expectedResult)
+	if callErr != nil {
+		c.pool.Release(e, callErr)
+		return nil, callErr
+	}
+	c.pool.Release(e, nil)
+	return response, nil
+}
+
+func (c *balancedClient) Close() {
+	c.pool.Close()
+}
+
+func NewBalancedClient(client Client, pool EndpointPool) BalancedClient {
+	return &balancedClient{
+		client: client,
+		pool:   pool,
+	}
+}
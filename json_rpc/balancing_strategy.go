@@ -0,0 +1,129 @@
+package json_rpc
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	default_hash_replicas = 100
+)
+
+type BalancingStrategy interface {
+	Pick(healthy []Endpoint, key string) Endpoint
+}
+
+type roundRobinStrategy struct {
+	next uint64
+}
+
+func (s *roundRobinStrategy) Pick(healthy []Endpoint, key string) Endpoint {
+	n := atomic.AddUint64(&s.next, 1) - 1
+	return healthy[n%uint64(len(healthy))]
+}
+
+type leastOutstandingStrategy struct {
+	roundRobin roundRobinStrategy
+}
+
+func (s *leastOutstandingStrategy) Pick(healthy []Endpoint, key string) Endpoint {
+	offset := int(atomic.AddUint64(&s.roundRobin.next, 1) % uint64(len(healthy)))
+	best := healthy[offset]
+	for i := 1; i < len(healthy); i++ {
+		candidate := healthy[(offset+i)%len(healthy)]
+		if candidate.GetOutstanding() < best.GetOutstanding() {
+			best = candidate
+		}
+	}
+	return best
+}
+
+type hashRingEntry struct {
+	hash  uint32
+	index int
+}
+
+type hashRing struct {
+	signature string
+	entries   []hashRingEntry
+}
+
+type consistentHashStrategy struct {
+	replicas int
+	fallback roundRobinStrategy
+	ring     atomic.Value
+}
+
+func ringSignature(healthy []Endpoint) string {
+	urls := make([]string, len(healthy))
+	for i := range healthy {
+		urls[i] = healthy[i].GetURL()
+	}
+	return strings.Join(urls, "\n")
+}
+
+func (s *consistentHashStrategy) buildRing(healthy []Endpoint, signature string) *hashRing {
+	entries := make([]hashRingEntry, 0, len(healthy)*s.replicas)
+	for i := range healthy {
+		url := healthy[i].GetURL()
+		for r := 0; r < s.replicas; r++ {
+			entries = append(entries, hashRingEntry{
+				hash:  crc32.ChecksumIEEE([]byte(url + "#" + strconv.Itoa(r))),
+				index: i,
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].hash < entries[j].hash
+	})
+	return &hashRing{
+		signature: signature,
+		entries:   entries,
+	}
+}
+
+func (s *consistentHashStrategy) getRing(healthy []Endpoint) *hashRing {
+	signature := ringSignature(healthy)
+	ring, ok := s.ring.Load().(*hashRing)
+	if ok && ring.signature == signature {
+		return ring
+	}
+	ring = s.buildRing(healthy, signature)
+	s.ring.Store(ring)
+	return ring
+}
+
+func (s *consistentHashStrategy) Pick(healthy []Endpoint, key string) Endpoint {
+	if len(key) <= 0 {
+		return s.fallback.Pick(healthy, key)
+	}
+	ring := s.getRing(healthy)
+	keyHash := crc32.ChecksumIEEE([]byte(key))
+	idx := sort.Search(len(ring.entries), func(i int) bool {
+		return ring.entries[i].hash >= keyHash
+	})
+	if idx >= len(ring.entries) {
+		idx = 0
+	}
+	return healthy[ring.entries[idx].index]
+}
+
+func NewRoundRobinStrategy() BalancingStrategy {
+	return &roundRobinStrategy{}
+}
+
+func NewLeastOutstandingStrategy() BalancingStrategy {
+	return &leastOutstandingStrategy{}
+}
+
+func NewConsistentHashStrategy(replicas int) BalancingStrategy {
+	if replicas <= 0 {
+		replicas = default_hash_replicas
+	}
+	return &consistentHashStrategy{
+		replicas: replicas,
+	}
+}
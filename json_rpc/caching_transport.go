@@ -0,0 +1,217 @@
+package json_rpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+type cacheDirectives struct {
+	noStore bool
+	noCache bool
+	maxAge  time.Duration
+	hasAge  bool
+}
+
+func parseCacheControl(header string) cacheDirectives {
+	res := cacheDirectives{}
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		switch strings.ToLower(kv[0]) {
+		case "no-store":
+			res.noStore = true
+		case "no-cache":
+			res.noCache = true
+		case "max-age":
+			if len(kv) != 2 {
+				continue
+			}
+			seconds, err := strconv.ParseInt(strings.Trim(kv[1], `"`), 10, 64)
+			if err != nil {
+				continue
+			}
+			res.maxAge = time.Duration(seconds) * time.Second
+			res.hasAge = true
+		}
+	}
+	return res
+}
+
+func credentialsScope(headers http.Header) string {
+	hash := sha256.Sum256([]byte(headers.Get("Authorization") + "\n" + headers.Get("Cookie")))
+	return hex.EncodeToString(hash[:])
+}
+
+type cachingTransport struct {
+	base     http.RoundTripper
+	cache    ResultCache
+	policies map[string]CachePolicy
+	now      func() time.Time
+}
+
+func (t *cachingTransport) freshFor(policy CachePolicy, headers http.Header) (time.Duration, bool) {
+	directives := parseCacheControl(headers.Get("Cache-Control"))
+	if directives.noStore {
+		return 0, false
+	}
+	ttl := policy.TTL
+	if directives.hasAge && (ttl <= 0 || directives.maxAge < ttl) {
+		ttl = directives.maxAge
+	}
+	if directives.noCache || ttl < 0 {
+		ttl = 0
+	}
+	return ttl, ttl > 0 || len(headers.Get("ETag")) > 0
+}
+
+func cachedBody(cached *CachedResult) ([]byte, bool) {
+	if cached == nil {
+		return nil, false
+	}
+	body, ok := cached.Data.([]byte)
+	return body, ok
+}
+
+func withResponseID(body []byte, id string) []byte {
+	envelope := map[string]json.RawMessage{}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return body
+	}
+	delete(envelope, "id")
+	if len(id) > 0 {
+		envelope["id"], err = json.Marshal(id)
+		if err != nil {
+			return body
+		}
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+func cachedHttpResponse(req *http.Request, cached *CachedResult, body []byte, id string) *http.Response {
+	body = withResponseID(body, id)
+	headers := http.Header{}
+	applyHeaders(headers, cached.Headers)
+	headers.Del("Content-Length")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to read request body for caching. Error: %v", err)
+	}
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = ioutil.NopCloser(bytes.NewReader(body))
+	outgoing.ContentLength = int64(len(body))
+
+	incomingRequest := struct {
+		RequestBase
+		Params json.RawMessage `json:"params,omitempty"`
+	}{}
+	err = json.Unmarshal(body, &incomingRequest)
+	if err != nil {
+		return t.base.RoundTrip(outgoing)
+	}
+	policy, ok := t.policies[incomingRequest.Method]
+	if !ok {
+		return t.base.RoundTrip(outgoing)
+	}
+	if policy.Key == nil {
+		policy.Key = DefaultCacheKey
+	}
+	key := req.URL.String() + "\n" + policy.Key(req.Context(), incomingRequest.Method, incomingRequest.Params)
+	if !policy.Shared {
+		key = credentialsScope(req.Header) + "\n" + key
+	}
+
+	now := t.now()
+	cached, _ := t.cache.Get(key)
+	cachedData, ok := cachedBody(cached)
+	if ok && now.Before(cached.ExpiresAt) {
+		return cachedHttpResponse(req, cached, cachedData, incomingRequest.ID), nil
+	}
+	if ok && len(cached.ETag) > 0 {
+		outgoing.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		ttl, _ := t.freshFor(policy, resp.Header)
+		refreshed := *cached
+		refreshed.ExpiresAt = now.Add(ttl)
+		t.cache.Set(key, &refreshed)
+		return cachedHttpResponse(req, &refreshed, cachedData, incomingRequest.ID), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	ttl, cacheable := t.freshFor(policy, resp.Header)
+	if !cacheable {
+		t.cache.Remove(key)
+		return resp, nil
+	}
+	respData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to read response for caching. Error: %v", err)
+	}
+	t.cache.Set(key, &CachedResult{
+		Data:      respData,
+		Headers:   resp.Header,
+		ETag:      resp.Header.Get("ETag"),
+		ExpiresAt: now.Add(ttl),
+	})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respData))
+	return resp, nil
+}
+
+func NewCachingTransport(base http.RoundTripper, cache ResultCache, policies map[string]CachePolicy) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cache == nil {
+		cache = NewLRUResultCache(default_cache_entries)
+	}
+	return &cachingTransport{
+		base:     base,
+		cache:    cache,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+func NewCachingClient(httpClient *http.Client, cache ResultCache, policies map[string]CachePolicy) Client {
+	caching := *httpClient
+	caching.Transport = NewCachingTransport(httpClient.Transport, cache, policies)
+	return NewClient(&caching)
+}
@@ -0,0 +1,81 @@
+package json_rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cachingTestResponse struct {
+	ID     string `json:"id"`
+	Result struct {
+		Calls int32 `json:"calls"`
+	} `json:"result"`
+}
+
+func newCachingTestServer(calls *int32, cacheControl string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := RequestBase{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		n := atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", cacheControl)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": JSON_RPC_VERSION,
+			"id":      request.ID,
+			"result": map[string]interface{}{
+				"calls": n,
+			},
+		})
+	}))
+}
+
+func TestCachingTransportRewritesReplayedID(t *testing.T) {
+	cases := []struct {
+		name         string
+		cacheControl string
+		ttl          time.Duration
+	}{
+		{name: "fresh entry", cacheControl: "max-age=60", ttl: time.Minute},
+		{name: "revalidated entry", cacheControl: "no-cache", ttl: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int32
+			server := newCachingTestServer(&calls, c.cacheControl)
+			defer server.Close()
+			httpClient := &http.Client{
+				Transport: NewCachingTransport(server.Client().Transport, nil, map[string]CachePolicy{
+					"get": {TTL: c.ttl, Shared: true},
+				}),
+			}
+			for _, id := range []string{"first", "second", "third"} {
+				resp, err := httpClient.Post(server.URL, ContentTypeJSON, strings.NewReader(`{"jsonrpc":"2.0","id":"`+id+`","method":"get","params":{"a":1}}`))
+				if err != nil {
+					t.Fatalf("Request %v failed. Error: %v", id, err)
+				}
+				response := cachingTestResponse{}
+				err = json.NewDecoder(resp.Body).Decode(&response)
+				resp.Body.Close()
+				if err != nil {
+					t.Fatalf("Failed to decode response %v. Error: %v", id, err)
+				}
+				if response.ID != id {
+					t.Fatalf("Expected response ID %v, got %v.", id, response.ID)
+				}
+				if response.Result.Calls != 1 {
+					t.Fatalf("Expected cached result, got calls=%v.", response.Result.Calls)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package json_rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	CancelRequestMethod = "$/cancelRequest"
+
+	status_client_closed_request = 499
+)
+
+type CancelParams struct {
+	ID string `json:"id"`
+}
+
+func MakeRequestCancelledError(err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 10, status_client_closed_request, "Request cancelled.", err)
+}
+
+type RequestTracker interface {
+	Track(ctx context.Context, id string) context.Context
+	Cancel(ctx context.Context, id string) bool
+	GetInFlight() []string
+}
+
+type trackedRequest struct {
+	id        string
+	cancel    context.CancelFunc
+	lock      sync.Mutex
+	cancelled bool
+}
+
+func (t *trackedRequest) markCancelled() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.cancelled = true
+}
+
+func (t *trackedRequest) isCancelled() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.cancelled
+}
+
+type trackedRequestKey struct{}
+
+type requestTracker struct {
+	lock     sync.Mutex
+	inFlight map[string][]*trackedRequest
+}
+
+func trackingKey(ctx context.Context, id string) string {
+	return principalScope(ctx, CancelRequestMethod, id)
+}
+
+func (t *requestTracker) remove(key string, entry *trackedRequest) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	entries := t.inFlight[key]
+	for i := range entries {
+		if entries[i] != entry {
+			continue
+		}
+		entries = append(entries[:i:i], entries[i+1:]...)
+		break
+	}
+	if len(entries) <= 0 {
+		delete(t.inFlight, key)
+		return
+	}
+	t.inFlight[key] = entries
+}
+
+func (t *requestTracker) Track(ctx context.Context, id string) context.Context {
+	if len(id) <= 0 {
+		return ctx
+	}
+	key := trackingKey(ctx, id)
+	ctx, cancel := context.WithCancel(ctx)
+	entry := &trackedRequest{
+		id:     id,
+		cancel: cancel,
+	}
+	t.lock.Lock()
+	t.inFlight[key] = append(t.inFlight[key], entry)
+	t.lock.Unlock()
+	go func() {
+		<-ctx.Done()
+		t.remove(key, entry)
+	}()
+	return context.WithValue(ctx, trackedRequestKey{}, entry)
+}
+
+func (t *requestTracker) Cancel(ctx context.Context, id string) bool {
+	key := trackingKey(ctx, id)
+	t.lock.Lock()
+	entries, ok := t.inFlight[key]
+	if ok {
+		delete(t.inFlight, key)
+	}
+	t.lock.Unlock()
+	if !ok {
+		return false
+	}
+	for i := range entries {
+		entries[i].markCancelled()
+		entries[i].cancel()
+	}
+	return true
+}
+
+func (t *requestTracker) GetInFlight() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	res := make([]string, 0, len(t.inFlight))
+	for _, entries := range t.inFlight {
+		for i := range entries {
+			res = append(res, entries[i].id)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func NewRequestTracker() RequestTracker {
+	return &requestTracker{
+		inFlight: map[string][]*trackedRequest{},
+	}
+}
+
+func IsRequestCancelled(ctx context.Context) bool {
+	entry, ok := ctx.Value(trackedRequestKey{}).(*trackedRequest)
+	return ok && entry.isCancelled()
+}
+
+type cancellableRpcHandlers struct {
+	RpcHandlers
+	tracker RequestTracker
+	cancel  HandlingInfo
+}
+
+func (r *cancellableRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	if name == CancelRequestMethod {
+		return r.cancel, true
+	}
+	handler, ok := r.RpcHandlers.GetHandler(name)
+	if !ok {
+		return handler, false
+	}
+	handler = withDefaults(handler)
+	compose := handler.ComposeContext
+	handle := handler.Handle
+	handler.ComposeContext = func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+		ctx, err := compose(ctx, request, rawHttpRequest)
+		if err != nil {
+			return ctx, err
+		}
+		return r.tracker.Track(ctx, request.ID), nil
+	}
+	handler.Handle = func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		response, err := handle(ctx, request)
+		if err != nil && IsRequestCancelled(ctx) {
+			return nil, MakeRequestCancelledError(errors.New(err.GetMessage()))
+		}
+		return response, err
+	}
+	return handler, true
+}
+
+func NewCancellableRpcHandlers(handlers RpcHandlers, tracker RequestTracker, authenticate Authenticator) RpcHandlers {
+	if tracker == nil {
+		tracker = NewRequestTracker()
+	}
+	return &cancellableRpcHandlers{
+		RpcHandlers: handlers,
+		tracker:     tracker,
+		cancel: withDefaults(HandlingInfo{
+			Authenticate: authenticate,
+			NewParams: func() interface{} {
+				return &CancelParams{}
+			},
+			Handle: func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+				params, ok := request.Data.(*CancelParams)
+				if !ok || len(params.ID) <= 0 {
+					return nil, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusBadRequest, "Missing request ID to cancel.", errors.New(CancelRequestMethod))
+				}
+				tracker.Cancel(ctx, params.ID)
+				return &ResponseInfo{}, nil
+			},
+		}),
+	}
+}
@@ -0,0 +1,72 @@
+package json_rpc
+
+import (
+	"sync"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/helpers"
+)
+
+type CircuitBreakerFactory func(url string, method string) helpers.CircuitBreaker
+
+type circuitBreakerClient struct {
+	client     Client
+	newBreaker CircuitBreakerFactory
+	isFailure  ErrorClassifier
+	lock       sync.Mutex
+	breakers   map[string]helpers.CircuitBreaker
+}
+
+func (c *circuitBreakerClient) getBreaker(url string, method string) helpers.CircuitBreaker {
+	key := url + "\n" + method
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	breaker, ok := c.breakers[key]
+	if ok {
+		return breaker
+	}
+	breaker = c.newBreaker(url, method)
+	c.breakers[key] = breaker
+	return breaker
+}
+
+func (c *circuitBreakerClient) Call(url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	breaker := c.getBreaker(url, method)
+	openErr := breaker.Allow()
+	if openErr != nil {
+		return nil, custom_error.WrapErrorf(openErr, "json-rpc call rejected. Method: '%v'. URL: '%v'.", method, url)
+	}
+	response, err := c.client.Call(url, method, args, expectedResult)
+	if err != nil {
+		if c.isFailure(err) {
+			breaker.Report(err)
+		} else {
+			breaker.Report(nil)
+		}
+		return nil, err
+	}
+	breaker.Report(nil)
+	return response, nil
+}
+
+func NewCircuitBreakerClient(client Client, newBreaker CircuitBreakerFactory) Client {
+	return NewClassifyingCircuitBreakerClient(client, newBreaker, nil)
+}
+
+func NewClassifyingCircuitBreakerClient(client Client, newBreaker CircuitBreakerFactory, isFailure ErrorClassifier) Client {
+	if isFailure == nil {
+		isFailure = IsTransportFailure
+	}
+	return &circuitBreakerClient{
+		client:     client,
+		newBreaker: newBreaker,
+		isFailure:  isFailure,
+		breakers:   map[string]helpers.CircuitBreaker{},
+	}
+}
+
+func NewDefaultCircuitBreakerClient(client Client, cfg helpers.CircuitBreakerConfig) Client {
+	return NewCircuitBreakerClient(client, func(url string, method string) helpers.CircuitBreaker {
+		return helpers.NewCircuitBreaker(cfg)
+	})
+}
@@ -0,0 +1,92 @@
+package json_rpc
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/helpers"
+)
+
+type failingTestClient struct {
+	err custom_error.CustomError
+}
+
+func (c *failingTestClient) Call(url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &UntypedResponse{}, nil
+}
+
+func newTestRemoteError(httpStatus int) custom_error.CustomError {
+	return newRemoteError(&remoteErrorEnvelope{
+		Code:    MakeErrorCode(json_rpc_module, 3),
+		Message: "Bad input.",
+	}, httpStatus)
+}
+
+func TestIsTransportFailure(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		failure bool
+	}{
+		{name: "nil", err: nil, failure: false},
+		{name: "transport error", err: custom_error.MakeErrorf("connection refused"), failure: true},
+		{name: "plain error", err: errors.New("EOF"), failure: true},
+		{name: "remote 400", err: newTestRemoteError(http.StatusBadRequest), failure: false},
+		{name: "remote 200 envelope", err: newTestRemoteError(http.StatusOK), failure: false},
+		{name: "remote 500", err: newTestRemoteError(http.StatusInternalServerError), failure: true},
+		{name: "http 404", err: newHttpStatusError(http.StatusNotFound, "404 Not Found", nil), failure: false},
+		{name: "http 503", err: newHttpStatusError(http.StatusServiceUnavailable, "503 Service Unavailable", nil), failure: true},
+		{name: "wrapped 502", err: custom_error.WrapErrorf(newHttpStatusError(http.StatusBadGateway, "502 Bad Gateway", nil), "Call failed."), failure: true},
+		{name: "circuit open", err: custom_error.MakeTypedErrorf(helpers.ErrorTypeCircuitOpen, "open"), failure: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if IsTransportFailure(c.err) != c.failure {
+				t.Fatalf("Expected failure=%v for %v.", c.failure, c.err)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerClientClassification(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      custom_error.CustomError
+		classify ErrorClassifier
+		expected helpers.CircuitState
+	}{
+		{name: "application errors keep the breaker closed", err: newTestRemoteError(http.StatusBadRequest), expected: helpers.CircuitClosed},
+		{name: "server errors open the breaker", err: newTestRemoteError(http.StatusInternalServerError), expected: helpers.CircuitOpen},
+		{name: "transport errors open the breaker", err: custom_error.MakeErrorf("connection refused"), expected: helpers.CircuitOpen},
+		{
+			name: "custom classifier",
+			err:  newTestRemoteError(http.StatusBadRequest),
+			classify: func(err error) bool {
+				return true
+			},
+			expected: helpers.CircuitOpen,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var breaker helpers.CircuitBreaker
+			client := NewClassifyingCircuitBreakerClient(&failingTestClient{err: c.err}, func(url string, method string) helpers.CircuitBreaker {
+				breaker = helpers.NewCircuitBreaker(helpers.CircuitBreakerConfig{
+					ConsecutiveFailures: 3,
+				})
+				return breaker
+			}, c.classify)
+			for i := 0; i < 3; i++ {
+				_, _ = client.Call("http://endpoint", "method", RPCArguments{}, nil)
+			}
+			if breaker.GetState() != c.expected {
+				t.Fatalf("Expected state %v, got %v.", c.expected, breaker.GetState())
+			}
+		})
+	}
+}
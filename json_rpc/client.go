@@ -2,8 +2,9 @@ package json_rpc
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"io/ioutil"
+	"mime"
 	"net/http"
 
 	"fmt"
@@ -26,13 +27,67 @@ type Client interface {
 	Call(url string, method string, args RPCArguments, expectedReult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError)
 }
 
+type CancellableClient interface {
+	Client
+	CallContext(ctx context.Context, url string, method string, args RPCArguments, expectedReult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError)
+}
+
 type client struct {
 	httpClient *http.Client
 	rpcVersion string
 	getID      IDFactory
+	codec      Codec
+}
+
+func (c *client) responseCodec(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == c.codec.GetContentType() {
+		return c.codec
+	}
+	if mediaType == ContentTypeJSON {
+		return NewJSONCodec(nil)
+	}
+	return c.codec
+}
+
+func (c *client) sendCancel(url string, requestID string, headers http.Header) {
+	notification := UntypedRequest{
+		RequestBase{
+			Method:  CancelRequestMethod,
+			Version: c.rpcVersion,
+		},
+		RequestParams{
+			Params: &CancelParams{
+				ID: requestID,
+			},
+		},
+	}
+	data, err := marshalWithCodec(c.codec, notification)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", c.codec.GetContentType())
+	req.Header.Set("Accept", c.codec.GetContentType())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
 }
 
-func (c *client) Call(url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (response *UntypedResponse, resError custom_error.CustomError) {
+func (c *client) Call(url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	return c.CallContext(context.Background(), url, method, args, expectedResult)
+}
+
+func (c *client) CallContext(ctx context.Context, url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (response *UntypedResponse, resError custom_error.CustomError) {
 	requestID := c.getID()
 	errorBuilder := custom_error.NewPrefixedErrorBuilder(fmt.Sprintf("json-rpc request ID: '%v'. Method: '%v'. URL: '%v'. ", requestID, method, url))
 	defer func() {
@@ -64,19 +119,20 @@ func (c *client) Call(url string, method string, args RPCArguments, expectedResu
 			Params: args.Data,
 		},
 	}
-	data, err := json.Marshal(request)
+	data, err := marshalWithCodec(c.codec, request)
 	if err != nil {
 		return nil, errorBuilder.MakeErrorf("Failed to marshal request. Error: %v", err)
 	}
 	r := bytes.NewReader(data)
-	req, err := http.NewRequest("POST", url, r)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, r)
 	if err != nil {
 		return nil, errorBuilder.MakeErrorf("Failed to create http-request. Error: %v", err)
 	}
 	if args.Headers != nil {
 		req.Header = args.Headers
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", c.codec.GetContentType())
+	req.Header.Set("Accept", c.codec.GetContentType())
 	for i := range args.Cookies {
 		req.AddCookie(args.Cookies[i])
 	}
@@ -86,21 +142,34 @@ func (c *client) Call(url string, method string, args RPCArguments, expectedResu
 		defer resp.Body.Close()
 	}
 	if err != nil {
+		if ctx.Err() != nil {
+			go c.sendCancel(url, requestID, args.Headers)
+			return nil, errorBuilder.MakeErrorf("Request cancelled. Error: %v", ctx.Err())
+		}
 		return nil, custom_error.MakeErrorf("Failed to send request. Error: %v", err)
 	}
-	if resp.StatusCode >= 400 {
-		return nil, custom_error.MakeErrorf("%v: %v", resp.StatusCode, resp.Status)
-	}
 	respData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, custom_error.MakeErrorf("Failed to read response. Error: %v", err)
+		if ctx.Err() != nil {
+			go c.sendCancel(url, requestID, args.Headers)
+			return nil, errorBuilder.MakeErrorf("Request cancelled. Error: %v", ctx.Err())
+		}
+		return nil, custom_error.MakeErrorf("Failed to read response. HTTP status: %v. Error: %v", resp.StatusCode, err)
+	}
+	codec := c.responseCodec(resp.Header.Get("Content-Type"))
+	remoteErr, ok := parseRemoteErrorWithCodec(codec, respData, resp.StatusCode)
+	if ok {
+		return nil, remoteErr
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newHttpStatusError(resp.StatusCode, resp.Status, respData)
 	}
 	responseBase := UntypedResponse{
 		ResponseResult: ResponseResult{
 			Result: expectedResult(),
 		},
 	}
-	err = json.Unmarshal(respData, &responseBase)
+	err = codec.NewDecoder(bytes.NewReader(respData)).Decode(&responseBase)
 	if err != nil {
 		return nil, custom_error.MakeErrorf("Failed to unmarshal response. Error: %v.", err)
 	}
@@ -113,9 +182,23 @@ func guidID() string {
 }
 
 func NewClient(httpClient *http.Client) Client {
+	return NewClientWithCodec(httpClient, NewJSONCodec(nil))
+}
+
+func NewCancellableClient(httpClient *http.Client) CancellableClient {
+	return &client{
+		httpClient: httpClient,
+		rpcVersion: JSON_RPC_VERSION,
+		getID:      guidID,
+		codec:      NewJSONCodec(nil),
+	}
+}
+
+func NewClientWithCodec(httpClient *http.Client, codec Codec) Client {
 	return &client{
 		httpClient: httpClient,
 		rpcVersion: JSON_RPC_VERSION,
 		getID:      guidID,
+		codec:      codec,
 	}
 }
@@ -0,0 +1,376 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeMsgPack = "application/msgpack"
+	ContentTypeCBOR    = "application/cbor"
+
+	struct_tag = "json"
+)
+
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+type Codec interface {
+	GetContentType() string
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type jsonCodec struct {
+	getDecoder DecoderFactory
+}
+
+func (c *jsonCodec) GetContentType() string {
+	return ContentTypeJSON
+}
+
+func (c *jsonCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (c *jsonCodec) NewDecoder(r io.Reader) Decoder {
+	return c.getDecoder(r)
+}
+
+func NewJSONCodec(decoderFactory DecoderFactory) Codec {
+	if decoderFactory == nil {
+		decoderFactory = defaultDecoder
+	}
+	return &jsonCodec{
+		getDecoder: decoderFactory,
+	}
+}
+
+type msgPackCodec struct {
+}
+
+func (c *msgPackCodec) GetContentType() string {
+	return ContentTypeMsgPack
+}
+
+func (c *msgPackCodec) NewEncoder(w io.Writer) Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag(struct_tag)
+	return enc
+}
+
+func (c *msgPackCodec) NewDecoder(r io.Reader) Decoder {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag(struct_tag)
+	return dec
+}
+
+func NewMsgPackCodec() Codec {
+	return &msgPackCodec{}
+}
+
+type cborCodec struct {
+	encMode cbor.EncMode
+	decMode cbor.DecMode
+}
+
+func (c *cborCodec) GetContentType() string {
+	return ContentTypeCBOR
+}
+
+func (c *cborCodec) NewEncoder(w io.Writer) Encoder {
+	return c.encMode.NewEncoder(w)
+}
+
+func (c *cborCodec) NewDecoder(r io.Reader) Decoder {
+	return &cborDecoder{
+		decMode: c.decMode,
+		dec:     c.decMode.NewDecoder(r),
+	}
+}
+
+type cborParams struct {
+	Params cbor.RawMessage `json:"params,omitempty"`
+}
+
+type cborResponse struct {
+	ResponseBase
+	Result cbor.RawMessage `json:"result,omitempty"`
+}
+
+type cborDecoder struct {
+	decMode cbor.DecMode
+	dec     *cbor.Decoder
+}
+
+func (d *cborDecoder) decodeInto(raw cbor.RawMessage, target interface{}) (interface{}, error) {
+	if len(raw) <= 0 {
+		return target, nil
+	}
+	if target == nil {
+		var v interface{}
+		err := d.decMode.Unmarshal(raw, &v)
+		return v, err
+	}
+	return target, d.decMode.Unmarshal(raw, target)
+}
+
+func (d *cborDecoder) Decode(v interface{}) error {
+	var err error
+	switch typed := v.(type) {
+	case *RequestParams:
+		params := cborParams{}
+		err = d.dec.Decode(&params)
+		if err != nil {
+			return err
+		}
+		typed.Params, err = d.decodeInto(params.Params, typed.Params)
+		return err
+	case *UntypedResponse:
+		response := cborResponse{}
+		err = d.dec.Decode(&response)
+		if err != nil {
+			return err
+		}
+		typed.ResponseBase = response.ResponseBase
+		typed.Result, err = d.decodeInto(response.Result, typed.Result)
+		return err
+	}
+	return d.dec.Decode(v)
+}
+
+func NewCBORCodec() Codec {
+	encMode, err := cbor.EncOptions{}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	decMode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return &cborCodec{
+		encMode: encMode,
+		decMode: decMode,
+	}
+}
+
+func isJSONCodec(codec Codec) bool {
+	return codec.GetContentType() == ContentTypeJSON
+}
+
+func marshalWithCodec(codec Codec, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := codec.NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var builtinCodecs = NewCodecs(NewJSONCodec(nil), NewMsgPackCodec(), NewCBORCodec())
+
+type rawParams struct {
+	data        []byte
+	contentType string
+}
+
+func (p *rawParams) UnmarshalJSON(data []byte) error {
+	p.data = append([]byte{}, data...)
+	p.contentType = ContentTypeJSON
+	return nil
+}
+
+func (p *rawParams) UnmarshalCBOR(data []byte) error {
+	p.data = append([]byte{}, data...)
+	p.contentType = ContentTypeCBOR
+	return nil
+}
+
+func (p *rawParams) DecodeMsgpack(dec *msgpack.Decoder) error {
+	data, err := dec.DecodeRaw()
+	if err != nil {
+		return err
+	}
+	p.data = data
+	p.contentType = ContentTypeMsgPack
+	return nil
+}
+
+func (p *rawParams) MarshalJSON() ([]byte, error) {
+	data, err := p.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= 0 {
+		return []byte("null"), nil
+	}
+	return data, nil
+}
+
+func (p *rawParams) isEmpty() bool {
+	return p == nil || len(p.data) <= 0
+}
+
+func (p *rawParams) decodeInto(ctx context.Context, target interface{}, getDecoder DecoderFactory) error {
+	codec, ok := GetRequestCodec(ctx)
+	if !ok {
+		codec = NewJSONCodec(getDecoder)
+	}
+	return codec.NewDecoder(bytes.NewReader(p.data)).Decode(target)
+}
+
+func (p *rawParams) toJSON() (json.RawMessage, error) {
+	if p.isEmpty() {
+		return nil, nil
+	}
+	if len(p.contentType) <= 0 || p.contentType == ContentTypeJSON {
+		return p.data, nil
+	}
+	codec, ok := builtinCodecs.ForContentType(p.contentType)
+	if !ok {
+		return nil, errors.New("Unsupported params content type: " + p.contentType)
+	}
+	var v interface{}
+	err := codec.NewDecoder(bytes.NewReader(p.data)).Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func normalizeJSONNumbers(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case json.Number:
+		i, err := typed.Int64()
+		if err == nil {
+			return i
+		}
+		f, _ := typed.Float64()
+		return f
+	case map[string]interface{}:
+		for k := range typed {
+			typed[k] = normalizeJSONNumbers(typed[k])
+		}
+	case []interface{}:
+		for i := range typed {
+			typed[i] = normalizeJSONNumbers(typed[i])
+		}
+	}
+	return v
+}
+
+func decodeRawJSON(data json.RawMessage) interface{} {
+	if len(data) <= 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil {
+		return data
+	}
+	return normalizeJSONNumbers(v)
+}
+
+type Codecs interface {
+	GetDefault() Codec
+	ForContentType(contentType string) (Codec, bool)
+	ForAccept(accept string) (Codec, bool)
+}
+
+type codecs struct {
+	ordered []Codec
+	byType  map[string]Codec
+}
+
+func (c *codecs) GetDefault() Codec {
+	return c.ordered[0]
+}
+
+func (c *codecs) ForContentType(contentType string) (Codec, bool) {
+	if len(contentType) <= 0 {
+		return c.GetDefault(), true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	codec, ok := c.byType[mediaType]
+	return codec, ok
+}
+
+func (c *codecs) ForAccept(accept string) (Codec, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return nil, false
+		}
+		codec, ok := c.byType[mediaType]
+		if ok {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+func NewCodecs(defaultCodec Codec, others ...Codec) Codecs {
+	res := &codecs{
+		ordered: append([]Codec{defaultCodec}, others...),
+		byType:  map[string]Codec{},
+	}
+	for i := len(res.ordered) - 1; i >= 0; i-- {
+		res.byType[res.ordered[i].GetContentType()] = res.ordered[i]
+	}
+	return res
+}
+
+type codecKey struct{}
+
+type negotiatedCodecs struct {
+	request  Codec
+	response Codec
+}
+
+func withNegotiatedCodecs(ctx context.Context, request Codec, response Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, &negotiatedCodecs{
+		request:  request,
+		response: response,
+	})
+}
+
+func GetRequestCodec(ctx context.Context) (Codec, bool) {
+	v, ok := ctx.Value(codecKey{}).(*negotiatedCodecs)
+	if !ok {
+		return nil, false
+	}
+	return v.request, true
+}
+
+func GetResponseCodec(ctx context.Context) (Codec, bool) {
+	v, ok := ctx.Value(codecKey{}).(*negotiatedCodecs)
+	if !ok {
+		return nil, false
+	}
+	return v.response, true
+}
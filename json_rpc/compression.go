@@ -0,0 +1,429 @@
+package json_rpc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+
+	encoding_identity             = "identity"
+	default_min_compress_size     = 1024
+	default_max_decompressed_size = 10 << 20
+)
+
+type CompressingWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+type Compressor interface {
+	GetEncoding() string
+	NewWriter(w io.Writer) (CompressingWriter, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+func (c *gzipCompressor) GetEncoding() string {
+	return EncodingGzip
+}
+
+func (c *gzipCompressor) NewWriter(w io.Writer) (CompressingWriter, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+func (c *gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func NewGzipCompressor(level int) Compressor {
+	return &gzipCompressor{
+		level: level,
+	}
+}
+
+type deflateCompressor struct {
+	level int
+}
+
+func (c *deflateCompressor) GetEncoding() string {
+	return EncodingDeflate
+}
+
+func (c *deflateCompressor) NewWriter(w io.Writer) (CompressingWriter, error) {
+	return zlib.NewWriterLevel(w, c.level)
+}
+
+func (c *deflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func NewDeflateCompressor(level int) Compressor {
+	return &deflateCompressor{
+		level: level,
+	}
+}
+
+func defaultCompressors() []Compressor {
+	return []Compressor{
+		NewGzipCompressor(gzip.DefaultCompression),
+		NewDeflateCompressor(flate.DefaultCompression),
+	}
+}
+
+func findCompressor(compressors []Compressor, encoding string) (Compressor, bool) {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	for i := range compressors {
+		if compressors[i].GetEncoding() == encoding {
+			return compressors[i], true
+		}
+	}
+	return nil, false
+}
+
+func negotiateEncoding(compressors []Compressor, acceptEncoding string) (Compressor, bool) {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) <= 0 {
+			continue
+		}
+		coding, params, err := mime.ParseMediaType("x/" + part)
+		if err != nil || params["q"] == "0" || params["q"] == "0.0" {
+			continue
+		}
+		coding = coding[len("x/"):]
+		if coding == "*" && len(compressors) > 0 {
+			return compressors[0], true
+		}
+		compressor, ok := findCompressor(compressors, coding)
+		if ok {
+			return compressor, true
+		}
+	}
+	return nil, false
+}
+
+type decompressingBody struct {
+	io.ReadCloser
+	source io.Closer
+}
+
+func (b *decompressingBody) Close() error {
+	err := b.ReadCloser.Close()
+	sourceErr := b.source.Close()
+	if err != nil {
+		return err
+	}
+	return sourceErr
+}
+
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	compressor  Compressor
+	minSize     int
+	status      int
+	buf         []byte
+	writer      CompressingWriter
+	wroteHeader bool
+}
+
+func (w *compressingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressingResponseWriter) forwardHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressingResponseWriter) startCompression() error {
+	if len(w.ResponseWriter.Header().Get("Content-Encoding")) > 0 {
+		w.forwardHeader()
+		return nil
+	}
+	writer, err := w.compressor.NewWriter(w.ResponseWriter)
+	if err != nil {
+		return err
+	}
+	w.ResponseWriter.Header().Set("Content-Encoding", w.compressor.GetEncoding())
+	w.ResponseWriter.Header().Del("Content-Length")
+	w.forwardHeader()
+	w.writer = writer
+	return nil
+}
+
+func (w *compressingResponseWriter) Write(data []byte) (int, error) {
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+	if w.wroteHeader {
+		return w.ResponseWriter.Write(data)
+	}
+	w.buf = append(w.buf, data...)
+	if len(w.buf) < w.minSize {
+		return len(data), nil
+	}
+	err := w.flushBuffer()
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *compressingResponseWriter) flushBuffer() error {
+	err := w.startCompression()
+	if err != nil {
+		return err
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.writer != nil {
+		_, err = w.writer.Write(buf)
+		return err
+	}
+	_, err = w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressingResponseWriter) Flush() {
+	if !w.wroteHeader && len(w.buf) > 0 {
+		_ = w.flushBuffer()
+	}
+	if w.writer != nil {
+		_ = w.writer.Flush()
+	}
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressingResponseWriter) Close() error {
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	if w.wroteHeader {
+		return nil
+	}
+	w.forwardHeader()
+	if len(w.buf) <= 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	return err
+}
+
+type CompressionConfig struct {
+	MinSize             int
+	MaxDecompressedSize int64
+	Compressors         []Compressor
+}
+
+func writeServerError(w http.ResponseWriter, serverError ServerError) {
+	var rpcError UntypedResponse
+	rpcError.Version = JSON_RPC_VERSION
+	rpcError.Err = serverError.ToError()
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(serverError.GetStatus())
+	_ = json.NewEncoder(w).Encode(rpcError)
+}
+
+func decompressBody(compressor Compressor, body io.ReadCloser, maxSize int64) ([]byte, ServerError) {
+	defer body.Close()
+	reader, err := compressor.NewReader(body)
+	if err != nil {
+		return nil, MakeErrorWithHttpStatus(json_rpc_module, 0, http.StatusBadRequest, "Failed to read request body.", err)
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, MakeErrorWithHttpStatus(json_rpc_module, 0, http.StatusBadRequest, "Failed to decompress request body.", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, MakeRequestTooLargeError("Decompressed request body is too large.", fmt.Errorf("Max size: %v", maxSize))
+	}
+	return data, nil
+}
+
+func NewCompressionHandler(handle func(w http.ResponseWriter, r *http.Request), config CompressionConfig) func(w http.ResponseWriter, r *http.Request) {
+	minSize := config.MinSize
+	if minSize <= 0 {
+		minSize = default_min_compress_size
+	}
+	maxDecompressedSize := config.MaxDecompressedSize
+	if maxDecompressedSize <= 0 {
+		maxDecompressedSize = default_max_decompressed_size
+	}
+	compressors := config.Compressors
+	if len(compressors) <= 0 {
+		compressors = defaultCompressors()
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		if len(encoding) > 0 && !strings.EqualFold(encoding, encoding_identity) {
+			compressor, ok := findCompressor(compressors, encoding)
+			if !ok {
+				writeServerError(w, MakeUnsupportedMediaTypeError("Unsupported content encoding.", errors.New(encoding)))
+				return
+			}
+			body, err := decompressBody(compressor, r.Body, maxDecompressedSize)
+			if err != nil {
+				writeServerError(w, err)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.Header.Del("Content-Encoding")
+			r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			r.ContentLength = int64(len(body))
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		compressor, ok := negotiateEncoding(compressors, r.Header.Get("Accept-Encoding"))
+		if !ok {
+			handle(w, r)
+			return
+		}
+		cw := &compressingResponseWriter{
+			ResponseWriter: w,
+			compressor:     compressor,
+			minSize:        minSize,
+		}
+		defer cw.Close()
+		handle(cw, r)
+	}
+}
+
+func CreateCompressedRpcHandler(handlers RpcHandlers, config CompressionConfig) func(w http.ResponseWriter, r *http.Request) {
+	return NewCompressionHandler(CreateJSONRpcHandler(handlers), config)
+}
+
+type compressionTransport struct {
+	base          http.RoundTripper
+	compressor    Compressor
+	minSize       int
+	decompressors []Compressor
+}
+
+func (t *compressionTransport) acceptEncoding() string {
+	encodings := make([]string, 0, len(t.decompressors))
+	for i := range t.decompressors {
+		encodings = append(encodings, t.decompressors[i].GetEncoding())
+	}
+	return strings.Join(encodings, ", ")
+}
+
+func (t *compressionTransport) compressBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || t.compressor == nil || len(req.Header.Get("Content-Encoding")) > 0 {
+		return req.Clone(req.Context()), nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to read request body for compression. Error: %v", err)
+	}
+	res := req.Clone(req.Context())
+	if len(body) < t.minSize {
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		return res, nil
+	}
+	var buf bytes.Buffer
+	writer, err := t.compressor.NewWriter(&buf)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to create compressor. Error: %v", err)
+	}
+	_, err = writer.Write(body)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to compress request body. Error: %v", err)
+	}
+	res.Body = ioutil.NopCloser(&buf)
+	res.ContentLength = int64(buf.Len())
+	res.Header.Set("Content-Encoding", t.compressor.GetEncoding())
+	return res, nil
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	compressed, err := t.compressBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed.Header.Get("Accept-Encoding")) <= 0 {
+		compressed.Header.Set("Accept-Encoding", t.acceptEncoding())
+	}
+	resp, err := t.base.RoundTrip(compressed)
+	if err != nil {
+		return resp, err
+	}
+	decompressor, ok := findCompressor(t.decompressors, resp.Header.Get("Content-Encoding"))
+	if !ok {
+		return resp, nil
+	}
+	reader, err := decompressor.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, custom_error.MakeErrorf("Failed to decompress response. Encoding: %v. Error: %v", decompressor.GetEncoding(), err)
+	}
+	resp.Body = &decompressingBody{
+		ReadCloser: reader,
+		source:     resp.Body,
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+func NewCompressionTransport(base http.RoundTripper, compressor Compressor, minSize int) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if minSize <= 0 {
+		minSize = default_min_compress_size
+	}
+	decompressors := defaultCompressors()
+	if compressor != nil {
+		decompressors = append([]Compressor{compressor}, decompressors...)
+	}
+	return &compressionTransport{
+		base:          base,
+		compressor:    compressor,
+		minSize:       minSize,
+		decompressors: decompressors,
+	}
+}
+
+func NewCompressingClient(httpClient *http.Client, compressor Compressor, minSize int) Client {
+	compressing := *httpClient
+	compressing.Transport = NewCompressionTransport(httpClient.Transport, compressor, minSize)
+	return NewClient(&compressing)
+}
@@ -0,0 +1,76 @@
+package json_rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type compressionTestParams struct {
+	Text string `json:"text"`
+}
+
+func gzipTestBody(t *testing.T, body string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(body))
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		t.Fatalf("Failed to compress body. Error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressedSizeLimit(t *testing.T) {
+	handlers := NewDefaultRpcHandlers(map[string]HandlingInfo{
+		"echo": {
+			NewParams: func() interface{} {
+				return &compressionTestParams{}
+			},
+			Handle: func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+				return &ResponseInfo{
+					Data: len(request.Data.(*compressionTestParams).Text),
+				}, nil
+			},
+		},
+	})
+	handle := CreateCompressedRpcHandler(handlers, CompressionConfig{
+		MaxDecompressedSize: 1024,
+	})
+	cases := []struct {
+		name   string
+		size   int
+		status int
+	}{
+		{name: "within limit", size: 512, status: http.StatusOK},
+		{name: "over limit", size: 4096, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body := `{"jsonrpc":"2.0","id":"1","method":"echo","params":{"text":"` + strings.Repeat("a", c.size) + `"}}`
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipTestBody(t, body)))
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			req.Header.Set("Content-Encoding", EncodingGzip)
+			w := httptest.NewRecorder()
+			handle(w, req)
+			if w.Code != c.status {
+				t.Fatalf("Expected status %v, got %v. Body: %v", c.status, w.Code, w.Body.String())
+			}
+			if c.status == http.StatusOK {
+				return
+			}
+			response := remoteErrorResponse{}
+			err := json.NewDecoder(w.Body).Decode(&response)
+			if err != nil || response.Err == nil || response.Err.Code != MakeErrorCode(json_rpc_module, 13) {
+				t.Fatalf("Unexpected error response: %+v. Error: %v", response.Err, err)
+			}
+		})
+	}
+}
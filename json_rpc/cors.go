@@ -0,0 +1,225 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	cors_allowed_methods = "POST, GET, OPTIONS"
+	cors_wildcard        = "*"
+)
+
+var (
+	defaultCORSAllowedHeaders = []string{
+		"Content-Type",
+		"Accept",
+		"Authorization",
+		"If-None-Match",
+		DefaultVersionHeader,
+		SignatureHeader,
+		IdempotencyKeyHeader,
+	}
+	defaultCORSExposedHeaders = []string{
+		"ETag",
+		"Cache-Control",
+		"Retry-After",
+		"Deprecation",
+		"Sunset",
+		"Link",
+		IdempotentReplayedHeader,
+	}
+)
+
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type corsPolicy struct {
+	origins          []string
+	anyOrigin        bool
+	allowedHeaders   string
+	anyHeader        bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+func matchOrigin(pattern string, origin string) bool {
+	if strings.EqualFold(pattern, origin) {
+		return true
+	}
+	i := strings.Index(pattern, cors_wildcard)
+	if i < 0 {
+		return false
+	}
+	prefix := strings.ToLower(pattern[:i])
+	suffix := strings.ToLower(pattern[i+1:])
+	origin = strings.ToLower(origin)
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+func (p *corsPolicy) isAllowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	for i := range p.origins {
+		if matchOrigin(p.origins[i], origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *corsPolicy) applyOrigin(headers http.Header, origin string) {
+	if p.anyOrigin {
+		headers.Set("Access-Control-Allow-Origin", cors_wildcard)
+	} else {
+		headers.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		headers.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func newCORSPolicy(config CORSConfig) (*corsPolicy, custom_error.CustomError) {
+	allowedHeaders := config.AllowedHeaders
+	if len(allowedHeaders) <= 0 {
+		allowedHeaders = defaultCORSAllowedHeaders
+	}
+	exposedHeaders := config.ExposedHeaders
+	if exposedHeaders == nil {
+		exposedHeaders = defaultCORSExposedHeaders
+	}
+	policy := &corsPolicy{
+		origins:          config.AllowedOrigins,
+		allowedHeaders:   strings.Join(allowedHeaders, ", "),
+		exposedHeaders:   strings.Join(exposedHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+	}
+	for i := range config.AllowedOrigins {
+		if config.AllowedOrigins[i] == cors_wildcard {
+			policy.anyOrigin = true
+		}
+	}
+	if policy.anyOrigin && policy.allowCredentials {
+		return nil, custom_error.MakeErrorf("CORS credentials require explicit allowed origins. Wildcard origin '%v' is not allowed.", cors_wildcard)
+	}
+	for i := range allowedHeaders {
+		if allowedHeaders[i] == cors_wildcard {
+			policy.anyHeader = true
+		}
+	}
+	if config.MaxAge > 0 {
+		policy.maxAge = strconv.FormatInt(int64(config.MaxAge/time.Second), 10)
+	}
+	return policy, nil
+}
+
+func NewCORSHandler(handle func(w http.ResponseWriter, r *http.Request), config CORSConfig) (func(w http.ResponseWriter, r *http.Request), custom_error.CustomError) {
+	policy, err := newCORSPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := len(origin) > 0 && policy.isAllowed(origin)
+		if r.Method != http.MethodOptions {
+			if allowed {
+				policy.applyOrigin(w.Header(), origin)
+				if len(policy.exposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", policy.exposedHeaders)
+				}
+			}
+			handle(w, r)
+			return
+		}
+		w.Header().Set("Allow", cors_allowed_methods)
+		if !allowed || len(r.Header.Get("Access-Control-Request-Method")) <= 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		policy.applyOrigin(w.Header(), origin)
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", cors_allowed_methods)
+		if policy.anyHeader {
+			requested := r.Header.Get("Access-Control-Request-Headers")
+			if len(requested) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", requested)
+			}
+		} else {
+			w.Header().Set("Access-Control-Allow-Headers", policy.allowedHeaders)
+		}
+		if len(policy.maxAge) > 0 {
+			w.Header().Set("Access-Control-Max-Age", policy.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}, nil
+}
+
+func NewGetRequestAdapter(handle func(w http.ResponseWriter, r *http.Request), handlers RpcHandlers) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			handle(w, r)
+			return
+		}
+		query := r.URL.Query()
+		method := query.Get("method")
+		if len(method) <= 0 {
+			writeServerError(w, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusBadRequest, "Missing method.", nil))
+			return
+		}
+		handler, ok := handlers.GetHandler(method)
+		if !ok || !handler.AllowGet {
+			w.Header().Set("Allow", "POST")
+			writeServerError(w, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusMethodNotAllowed, "Method is not available via GET: "+method, nil))
+			return
+		}
+		var params json.RawMessage
+		rawParams := query.Get("params")
+		if len(rawParams) > 0 {
+			if !json.Valid([]byte(rawParams)) {
+				writeServerError(w, MakeErrorWithHttpStatus(json_rpc_module, 1, http.StatusBadRequest, "Failed to parse request params.", errors.New(rawParams)))
+				return
+			}
+			params = json.RawMessage(rawParams)
+		}
+		body, err := json.Marshal(UntypedRequest{
+			RequestBase{
+				Version: JSON_RPC_VERSION,
+				ID:      query.Get("id"),
+				Method:  method,
+			},
+			RequestParams{
+				Params: params,
+			},
+		})
+		if err != nil {
+			writeServerError(w, MakeErrorWithHttpStatus(json_rpc_module, 1, http.StatusBadRequest, "Failed to build request.", err))
+			return
+		}
+		converted := r.Clone(r.Context())
+		converted.Body = ioutil.NopCloser(bytes.NewReader(body))
+		converted.ContentLength = int64(len(body))
+		converted.Header.Set("Content-Type", ContentTypeJSON)
+		handle(w, converted)
+	}
+}
+
+func CreateBrowserRpcHandler(handlers RpcHandlers, config CORSConfig) (func(w http.ResponseWriter, r *http.Request), custom_error.CustomError) {
+	return NewCORSHandler(NewGetRequestAdapter(CreateJSONRpcHandler(handlers), handlers), config)
+}
@@ -0,0 +1,269 @@
+package json_rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/helpers"
+)
+
+const (
+	DefaultHealthMethod = "system.health"
+
+	default_failure_threshold = 3
+	default_probe_interval    = 5 * time.Second
+	default_probe_timeout     = time.Second
+)
+
+type HealthProbe func(ctx context.Context, url string) bool
+type ErrorClassifier func(err error) bool
+
+type httpStatusCarrier interface {
+	GetStatus() int
+}
+
+func IsTransportFailure(err error) bool {
+	if err == nil || helpers.IsCircuitOpenError(err) {
+		return false
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		carrier, ok := e.(httpStatusCarrier)
+		if ok {
+			return carrier.GetStatus() >= http.StatusInternalServerError
+		}
+	}
+	return true
+}
+
+type Endpoint interface {
+	GetURL() string
+	IsHealthy() bool
+	GetOutstanding() int64
+}
+
+type EndpointPool interface {
+	Acquire(key string) (Endpoint, custom_error.CustomError)
+	Release(e Endpoint, callErr error)
+	GetEndpoints() []Endpoint
+	Close()
+}
+
+type EndpointPoolConfig struct {
+	URLs             []string
+	Strategy         BalancingStrategy
+	FailureThreshold int
+	ProbeInterval    time.Duration
+	ProbeTimeout     time.Duration
+	Probe            HealthProbe
+	IsFailure        ErrorClassifier
+}
+
+type endpoint struct {
+	url         string
+	outstanding int64
+	failures    int32
+	ejected     int32
+}
+
+func (e *endpoint) GetURL() string {
+	return e.url
+}
+
+func (e *endpoint) IsHealthy() bool {
+	return atomic.LoadInt32(&e.ejected) == 0
+}
+
+func (e *endpoint) GetOutstanding() int64 {
+	return atomic.LoadInt64(&e.outstanding)
+}
+
+type endpointPool struct {
+	endpoints        []*endpoint
+	strategy         BalancingStrategy
+	failureThreshold int32
+	probe            HealthProbe
+	isFailure        ErrorClassifier
+	probeInterval    time.Duration
+	probeTimeout     time.Duration
+	stop             chan struct{}
+	stopOnce         sync.Once
+	wg               sync.WaitGroup
+}
+
+func (p *endpointPool) Acquire(key string) (Endpoint, custom_error.CustomError) {
+	healthy := make([]Endpoint, 0, len(p.endpoints))
+	for i := range p.endpoints {
+		if p.endpoints[i].IsHealthy() {
+			healthy = append(healthy, p.endpoints[i])
+		}
+	}
+	if len(healthy) <= 0 {
+		return nil, custom_error.MakeErrorf("No healthy endpoints available. Total endpoints: %v", len(p.endpoints))
+	}
+	picked := p.strategy.Pick(healthy, key)
+	e, ok := picked.(*endpoint)
+	if !ok {
+		return nil, custom_error.MakeErrorf("Balancing strategy returned unknown endpoint: %T", picked)
+	}
+	atomic.AddInt64(&e.outstanding, 1)
+	return e, nil
+}
+
+func (p *endpointPool) Release(picked Endpoint, callErr error) {
+	e, ok := picked.(*endpoint)
+	if !ok {
+		return
+	}
+	atomic.AddInt64(&e.outstanding, -1)
+	if callErr == nil {
+		atomic.StoreInt32(&e.failures, 0)
+		return
+	}
+	if !p.isFailure(callErr) {
+		return
+	}
+	if atomic.AddInt32(&e.failures, 1) >= p.failureThreshold {
+		atomic.StoreInt32(&e.ejected, 1)
+	}
+}
+
+func (p *endpointPool) GetEndpoints() []Endpoint {
+	res := make([]Endpoint, 0, len(p.endpoints))
+	for i := range p.endpoints {
+		res = append(res, p.endpoints[i])
+	}
+	return res
+}
+
+func (p *endpointPool) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+}
+
+func (p *endpointPool) probeEjected() {
+	for i := range p.endpoints {
+		e := p.endpoints[i]
+		if e.IsHealthy() {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.probeTimeout)
+		alive := p.probe(ctx, e.url)
+		cancel()
+		if !alive {
+			continue
+		}
+		atomic.StoreInt32(&e.failures, 0)
+		atomic.StoreInt32(&e.ejected, 0)
+	}
+}
+
+func (p *endpointPool) runProbes() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.probeEjected()
+		}
+	}
+}
+
+func isSuccessStatus(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+func NewHttpHealthProbe(httpClient *http.Client, method string, path string) HealthProbe {
+	if len(method) <= 0 {
+		method = http.MethodGet
+	}
+	return func(ctx context.Context, endpointURL string) bool {
+		target, err := url.Parse(endpointURL)
+		if err != nil {
+			return false
+		}
+		if len(path) > 0 {
+			ref, err := url.Parse(path)
+			if err != nil {
+				return false
+			}
+			target = target.ResolveReference(ref)
+		}
+		req, err := http.NewRequest(method, target.String(), nil)
+		if err != nil {
+			return false
+		}
+		resp, err := httpClient.Do(req.WithContext(ctx))
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err != nil {
+			return false
+		}
+		return isSuccessStatus(resp.StatusCode)
+	}
+}
+
+func NewRpcHealthProbe(client CancellableClient, method string) HealthProbe {
+	if len(method) <= 0 {
+		method = DefaultHealthMethod
+	}
+	return func(ctx context.Context, endpointURL string) bool {
+		_, err := client.CallContext(ctx, endpointURL, method, RPCArguments{}, func() interface{} {
+			return nil
+		})
+		return err == nil
+	}
+}
+
+func NewEndpointPool(cfg EndpointPoolConfig) (EndpointPool, custom_error.CustomError) {
+	if len(cfg.URLs) <= 0 {
+		return nil, custom_error.MakeErrorf("Endpoint pool requires at least one URL.")
+	}
+	pool := &endpointPool{
+		endpoints:        make([]*endpoint, 0, len(cfg.URLs)),
+		strategy:         cfg.Strategy,
+		failureThreshold: int32(cfg.FailureThreshold),
+		probe:            cfg.Probe,
+		isFailure:        cfg.IsFailure,
+		probeInterval:    cfg.ProbeInterval,
+		probeTimeout:     cfg.ProbeTimeout,
+		stop:             make(chan struct{}),
+	}
+	for i := range cfg.URLs {
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			url: cfg.URLs[i],
+		})
+	}
+	if pool.strategy == nil {
+		pool.strategy = NewRoundRobinStrategy()
+	}
+	if pool.failureThreshold <= 0 {
+		pool.failureThreshold = default_failure_threshold
+	}
+	if pool.probe == nil {
+		pool.probe = NewRpcHealthProbe(NewCancellableClient(http.DefaultClient), DefaultHealthMethod)
+	}
+	if pool.isFailure == nil {
+		pool.isFailure = IsTransportFailure
+	}
+	if pool.probeInterval <= 0 {
+		pool.probeInterval = default_probe_interval
+	}
+	if pool.probeTimeout <= 0 {
+		pool.probeTimeout = default_probe_timeout
+	}
+	pool.wg.Add(1)
+	go pool.runProbes()
+	return pool, nil
+}
@@ -1,6 +1,7 @@
 package json_rpc
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -24,6 +25,10 @@ type ServerError interface {
 	Unwrap() error
 }
 
+type ServerErrorHeaders interface {
+	GetHeaders() http.Header
+}
+
 type serverErrorImpl struct {
 	code       int64
 	message    string
@@ -67,6 +72,22 @@ func (e *serverErrorImpl) String() string {
 	return fmt.Sprintf("ServerError. Code: %v. Message: %v. Data: %v", e.GetCode(), e.GetMessage(), e.GetData())
 }
 
+type headeredServerError struct {
+	ServerError
+	headers http.Header
+}
+
+func (e *headeredServerError) GetHeaders() http.Header {
+	return e.headers
+}
+
+func WithErrorHeaders(err ServerError, headers http.Header) ServerError {
+	return &headeredServerError{
+		ServerError: err,
+		headers:     headers,
+	}
+}
+
 func MakeError(module int, errorCode int, message string, err error) ServerError {
 	return MakeErrorWithHttpStatus(module, errorCode, http.StatusInternalServerError, message, err)
 }
@@ -100,3 +121,41 @@ func ThrowErrorWithHttpStatus(module int, errorCode int, httpStatus int, message
 func MakeErrorCode(module int, errorCode int) int64 {
 	return (int64(module) << module_sh) + int64(errorCode)
 }
+
+func SplitErrorCode(code int64) (module int, errorCode int) {
+	low := int64(int32(uint32(code)))
+	return int((code - low) >> module_sh), int(low)
+}
+
+func GetErrorModule(code int64) int {
+	module, _ := SplitErrorCode(code)
+	return module
+}
+
+func GetModuleErrorCode(code int64) int {
+	_, errorCode := SplitErrorCode(code)
+	return errorCode
+}
+
+func MakeInvalidParamsError(method string, params interface{}) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 1, http.StatusBadRequest, "Invalid request params.", fmt.Errorf("Method: %v. Params type: %T", method, params))
+}
+
+func MakeUnsupportedMediaTypeError(message string, err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 12, http.StatusUnsupportedMediaType, message, err)
+}
+
+func MakeRequestTooLargeError(message string, err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 13, http.StatusRequestEntityTooLarge, message, err)
+}
+
+func ToServerError(err error) ServerError {
+	if err == nil {
+		return nil
+	}
+	var serverError ServerError
+	if errors.As(err, &serverError) {
+		return serverError
+	}
+	return MakeError(json_rpc_module, 6, "Handler failed.", err)
+}
@@ -0,0 +1,145 @@
+package json_rpc
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	json_rpc_module      = 0
+	json_rpc_module_name = "json_rpc"
+)
+
+type ErrorDescription struct {
+	Module     int
+	ModuleName string
+	Code       int
+	Name       string
+	Known      bool
+}
+
+func (d ErrorDescription) String() string {
+	module := d.ModuleName
+	if len(module) <= 0 {
+		module = fmt.Sprintf("module(%v)", d.Module)
+	}
+	name := d.Name
+	if !d.Known {
+		name = fmt.Sprintf("code(%v)", d.Code)
+	}
+	return module + "/" + name
+}
+
+type ErrorRegistry interface {
+	RegisterModule(module int, name string, errors map[int]string) custom_error.CustomError
+	Describe(code int64) ErrorDescription
+	Format(err ServerError) string
+	NewErrorComposer(module int) ErrorComposer
+}
+
+type errorModule struct {
+	name   string
+	errors map[int]string
+}
+
+type errorRegistry struct {
+	lock    sync.RWMutex
+	modules map[int]errorModule
+}
+
+func (r *errorRegistry) RegisterModule(module int, name string, errors map[int]string) custom_error.CustomError {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	existing, ok := r.modules[module]
+	if ok && existing.name != name {
+		return custom_error.MakeErrorf("Error module %v is already registered as '%v'. Requested name: '%v'", module, existing.name, name)
+	}
+	table := make(map[int]string, len(errors))
+	for k, v := range errors {
+		table[k] = v
+	}
+	r.modules[module] = errorModule{
+		name:   name,
+		errors: table,
+	}
+	return nil
+}
+
+func (r *errorRegistry) Describe(code int64) ErrorDescription {
+	module, errorCode := SplitErrorCode(code)
+	res := ErrorDescription{
+		Module: module,
+		Code:   errorCode,
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	m, ok := r.modules[module]
+	if !ok {
+		return res
+	}
+	res.ModuleName = m.name
+	res.Name, res.Known = m.errors[errorCode]
+	return res
+}
+
+func (r *errorRegistry) Format(err ServerError) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v: %v", r.Describe(err.GetCode()), err.GetMessage())
+}
+
+func (r *errorRegistry) NewErrorComposer(module int) ErrorComposer {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return NewDefaultErrorComposer(module, r.modules[module].errors)
+}
+
+func NewErrorRegistry() ErrorRegistry {
+	return &errorRegistry{
+		modules: map[int]errorModule{},
+	}
+}
+
+var defaultErrorRegistry ErrorRegistry
+
+func GetErrorRegistry() ErrorRegistry {
+	return defaultErrorRegistry
+}
+
+func RegisterErrorModule(module int, name string, errors map[int]string) custom_error.CustomError {
+	return defaultErrorRegistry.RegisterModule(module, name, errors)
+}
+
+func DescribeErrorCode(code int64) ErrorDescription {
+	return defaultErrorRegistry.Describe(code)
+}
+
+func FormatServerError(err ServerError) string {
+	return defaultErrorRegistry.Format(err)
+}
+
+func init() {
+	defaultErrorRegistry = NewErrorRegistry()
+	cErr := defaultErrorRegistry.RegisterModule(json_rpc_module, json_rpc_module_name, map[int]string{
+		0:  "READ_REQUEST_FAILED",
+		1:  "PARSE_REQUEST_FAILED",
+		2:  "UNSUPPORTED_VERSION",
+		3:  "INVALID_REQUEST",
+		4:  "WRITE_RESPONSE_FAILED",
+		5:  "EMPTY_RESPONSE",
+		6:  "HANDLER_FAILED",
+		7:  "UNAUTHORIZED",
+		8:  "FORBIDDEN",
+		9:  "RATE_LIMITED",
+		10: "REQUEST_CANCELLED",
+		11: "IDEMPOTENCY_CONFLICT",
+		12: "UNSUPPORTED_MEDIA_TYPE",
+		13: "REQUEST_TOO_LARGE",
+	})
+	if cErr != nil {
+		panic(cErr)
+	}
+}
@@ -0,0 +1,189 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+type RegistryChangeKind int
+
+const (
+	MethodRegistered RegistryChangeKind = iota
+	MethodUnregistered
+	MethodsReplaced
+)
+
+type RegistryChange struct {
+	Kind    RegistryChangeKind
+	Methods []string
+}
+
+type RegistryListener func(change RegistryChange)
+
+type HandlerRegistry interface {
+	RpcHandlers
+	Register(name string, handler HandlingInfo) custom_error.CustomError
+	Unregister(name string) bool
+	ReplaceAll(handlers map[string]HandlingInfo) custom_error.CustomError
+	GetMethods() []string
+	AddListener(listener RegistryListener)
+}
+
+type handlerRegistry struct {
+	handlers       atomic.Value
+	writeLock      sync.Mutex
+	listenersLock  sync.RWMutex
+	listeners      []RegistryListener
+	defaultHeaders HeadersFromContext
+	getDecoder     DecoderFactory
+	newContext     ContextFactory
+}
+
+func (r *handlerRegistry) load() map[string]HandlingInfo {
+	return r.handlers.Load().(map[string]HandlingInfo)
+}
+
+func (r *handlerRegistry) copyHandlers() map[string]HandlingInfo {
+	current := r.load()
+	res := make(map[string]HandlingInfo, len(current)+1)
+	for k, v := range current {
+		res[k] = v
+	}
+	return res
+}
+
+func (r *handlerRegistry) notify(change RegistryChange) {
+	r.listenersLock.RLock()
+	listeners := r.listeners
+	r.listenersLock.RUnlock()
+	for i := range listeners {
+		listeners[i](change)
+	}
+}
+
+func validateHandler(name string, handler HandlingInfo) custom_error.CustomError {
+	if handler.Handle == nil || handler.NewParams == nil {
+		return custom_error.MakeErrorf("Handler for method '%v' must define Handle and NewParams.", name)
+	}
+	return nil
+}
+
+func (r *handlerRegistry) Register(name string, handler HandlingInfo) custom_error.CustomError {
+	err := validateHandler(name, handler)
+	if err != nil {
+		return err
+	}
+	r.writeLock.Lock()
+	updated := r.copyHandlers()
+	_, exists := updated[name]
+	if exists {
+		r.writeLock.Unlock()
+		return custom_error.MakeErrorf("Method '%v' is already registered.", name)
+	}
+	updated[name] = withDefaults(handler)
+	r.handlers.Store(updated)
+	r.writeLock.Unlock()
+	r.notify(RegistryChange{
+		Kind:    MethodRegistered,
+		Methods: []string{name},
+	})
+	return nil
+}
+
+func (r *handlerRegistry) Unregister(name string) bool {
+	r.writeLock.Lock()
+	updated := r.copyHandlers()
+	_, exists := updated[name]
+	if !exists {
+		r.writeLock.Unlock()
+		return false
+	}
+	delete(updated, name)
+	r.handlers.Store(updated)
+	r.writeLock.Unlock()
+	r.notify(RegistryChange{
+		Kind:    MethodUnregistered,
+		Methods: []string{name},
+	})
+	return true
+}
+
+func (r *handlerRegistry) ReplaceAll(handlers map[string]HandlingInfo) custom_error.CustomError {
+	updated := make(map[string]HandlingInfo, len(handlers))
+	for _, k := range sortedMethods(handlers) {
+		err := validateHandler(k, handlers[k])
+		if err != nil {
+			return custom_error.WrapErrorf(err, "Failed to replace handlers. No changes applied.")
+		}
+		updated[k] = withDefaults(handlers[k])
+	}
+	r.writeLock.Lock()
+	r.handlers.Store(updated)
+	r.writeLock.Unlock()
+	r.notify(RegistryChange{
+		Kind:    MethodsReplaced,
+		Methods: sortedMethods(updated),
+	})
+	return nil
+}
+
+func (r *handlerRegistry) GetMethods() []string {
+	return sortedMethods(r.load())
+}
+
+func (r *handlerRegistry) AddListener(listener RegistryListener) {
+	r.listenersLock.Lock()
+	defer r.listenersLock.Unlock()
+	r.listeners = append(append([]RegistryListener{}, r.listeners...), listener)
+}
+
+func (r *handlerRegistry) GetHandler(name string) (HandlingInfo, bool) {
+	v, ok := r.load()[name]
+	return v, ok
+}
+
+func (r *handlerRegistry) GetHeaders(ctx context.Context) http.Header {
+	return r.defaultHeaders(ctx)
+}
+
+func (r *handlerRegistry) GetDecoder(data io.Reader) *json.Decoder {
+	return r.getDecoder(data)
+}
+
+func (r *handlerRegistry) NewContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return r.newContext(ctx)
+}
+
+func sortedMethods(handlers map[string]HandlingInfo) []string {
+	res := make([]string, 0, len(handlers))
+	for k := range handlers {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func NewDefaultHandlerRegistry(handlers map[string]HandlingInfo) HandlerRegistry {
+	return NewHandlerRegistry(handlers, dummyHeaders, defaultCtxFactory, defaultDecoder)
+}
+
+func NewHandlerRegistry(handlers map[string]HandlingInfo, defaultHeaders HeadersFromContext, contextFactory ContextFactory, decoderFactory DecoderFactory) HandlerRegistry {
+	registry := &handlerRegistry{
+		defaultHeaders: defaultHeaders,
+		getDecoder:     decoderFactory,
+		newContext:     contextFactory,
+	}
+	initial := make(map[string]HandlingInfo, len(handlers))
+	for k, v := range handlers {
+		initial[k] = withDefaults(v)
+	}
+	registry.handlers.Store(initial)
+	return registry
+}
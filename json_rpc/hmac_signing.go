@@ -0,0 +1,298 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	SignatureHeader = "X-Rpc-Signature"
+
+	default_max_clock_skew = 5 * time.Minute
+	nonce_size             = 16
+	signature_key_id       = "key"
+	signature_timestamp    = "ts"
+	signature_nonce        = "nonce"
+	signature_value        = "sig"
+)
+
+type signatureKeyIDKey struct{}
+
+type NonceStore interface {
+	Remember(nonce string, expiresAt time.Time) bool
+}
+
+type memoryNonceStore struct {
+	lock   sync.Mutex
+	nonces map[string]time.Time
+	calls  uint64
+	now    func() time.Time
+}
+
+func (s *memoryNonceStore) sweep(now time.Time) {
+	for k, v := range s.nonces {
+		if v.Before(now) {
+			delete(s.nonces, k)
+		}
+	}
+}
+
+func (s *memoryNonceStore) Remember(nonce string, expiresAt time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	s.calls++
+	if s.calls%sweep_interval == 0 {
+		s.sweep(now)
+	}
+	seenUntil, seen := s.nonces[nonce]
+	if seen && !seenUntil.Before(now) {
+		return false
+	}
+	s.nonces[nonce] = expiresAt
+	return true
+}
+
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		nonces: map[string]time.Time{},
+		now:    time.Now,
+	}
+}
+
+func signaturePayload(method string, timestamp string, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	return []byte(method + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:]))
+}
+
+func computeSignature(key []byte, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func parseSignatureHeader(header string) map[string]string {
+	res := map[string]string{}
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		res[kv[0]] = kv[1]
+	}
+	return res
+}
+
+type hmacSigningTransport struct {
+	base  http.RoundTripper
+	keyID string
+	key   []byte
+	now   func() time.Time
+}
+
+func (t *hmacSigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, custom_error.MakeErrorf("Failed to read request body for signing. Error: %v", err)
+		}
+		body = data
+	}
+	incomingRequest := RequestBase{}
+	codec, ok := builtinCodecs.ForContentType(req.Header.Get("Content-Type"))
+	if ok {
+		_ = codec.NewDecoder(bytes.NewReader(body)).Decode(&incomingRequest)
+	}
+	nonce := make([]byte, nonce_size)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to generate nonce. Error: %v", err)
+	}
+	nonceValue := hex.EncodeToString(nonce)
+	timestamp := strconv.FormatInt(t.now().Unix(), 10)
+	signature := computeSignature(t.key, signaturePayload(incomingRequest.Method, timestamp, nonceValue, body))
+
+	signed := req.Clone(req.Context())
+	signed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	signed.ContentLength = int64(len(body))
+	signed.Header.Set(SignatureHeader, fmt.Sprintf("%v=%v,%v=%v,%v=%v,%v=%v",
+		signature_key_id, t.keyID,
+		signature_timestamp, timestamp,
+		signature_nonce, nonceValue,
+		signature_value, base64.StdEncoding.EncodeToString(signature)))
+	return t.base.RoundTrip(signed)
+}
+
+func NewHMACSigningTransport(base http.RoundTripper, keyID string, key []byte) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &hmacSigningTransport{
+		base:  base,
+		keyID: keyID,
+		key:   key,
+		now:   time.Now,
+	}
+}
+
+func NewHMACSigningClient(httpClient *http.Client, keyID string, key []byte) Client {
+	signed := *httpClient
+	signed.Transport = NewHMACSigningTransport(httpClient.Transport, keyID, key)
+	return NewClient(&signed)
+}
+
+type HMACVerifier interface {
+	AddKey(keyID string, key []byte)
+	RemoveKey(keyID string)
+	SetKeyScopes(keyID string, scopes []string)
+	Verify(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (string, ServerError)
+	ContextBuilder() ContextBuilder
+	Authenticator() Authenticator
+}
+
+type hmacVerifier struct {
+	lock    sync.RWMutex
+	keys    map[string][]byte
+	scopes  map[string][]string
+	maxSkew time.Duration
+	nonces  NonceStore
+	now     func() time.Time
+}
+
+func (v *hmacVerifier) AddKey(keyID string, key []byte) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.keys[keyID] = append([]byte{}, key...)
+}
+
+func (v *hmacVerifier) RemoveKey(keyID string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.keys, keyID)
+	delete(v.scopes, keyID)
+}
+
+func (v *hmacVerifier) SetKeyScopes(keyID string, scopes []string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.scopes[keyID] = append([]string{}, scopes...)
+}
+
+func (v *hmacVerifier) getScopes(keyID string) []string {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return v.scopes[keyID]
+}
+
+func (v *hmacVerifier) getKey(keyID string) ([]byte, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	key, ok := v.keys[keyID]
+	return key, ok
+}
+
+func (v *hmacVerifier) Verify(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (string, ServerError) {
+	header := rawHttpRequest.Header.Get(SignatureHeader)
+	if len(header) <= 0 {
+		return "", MakeUnauthorizedError("Missing request signature.", nil)
+	}
+	fields := parseSignatureHeader(header)
+	keyID := fields[signature_key_id]
+	key, ok := v.getKey(keyID)
+	if !ok {
+		return "", MakeUnauthorizedError("Unknown signing key.", errors.New("Key ID: "+keyID))
+	}
+	timestamp := fields[signature_timestamp]
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", MakeUnauthorizedError("Invalid signature timestamp.", err)
+	}
+	signedAt := time.Unix(unix, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return "", MakeUnauthorizedError("Stale request signature.", fmt.Errorf("Signed at: %v. Now: %v", signedAt, now))
+	}
+	signature, err := base64.StdEncoding.DecodeString(fields[signature_value])
+	if err != nil {
+		return "", MakeUnauthorizedError("Invalid signature encoding.", err)
+	}
+	var body []byte
+	if rawHttpRequest.Body != nil {
+		body, err = ioutil.ReadAll(rawHttpRequest.Body)
+		if err != nil {
+			return "", MakeUnauthorizedError("Failed to read request body.", err)
+		}
+		rawHttpRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	nonce := fields[signature_nonce]
+	expected := computeSignature(key, signaturePayload(request.Method, timestamp, nonce, body))
+	if !hmac.Equal(signature, expected) {
+		return "", MakeUnauthorizedError("Invalid request signature.", errors.New("Key ID: "+keyID))
+	}
+	if len(nonce) <= 0 || !v.nonces.Remember(keyID+":"+nonce, signedAt.Add(2*v.maxSkew)) {
+		return "", MakeUnauthorizedError("Replayed request.", errors.New("Nonce: "+nonce))
+	}
+	return keyID, nil
+}
+
+func (v *hmacVerifier) ContextBuilder() ContextBuilder {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+		keyID, err := v.Verify(ctx, request, rawHttpRequest)
+		if err != nil {
+			return ctx, err
+		}
+		return context.WithValue(ctx, signatureKeyIDKey{}, keyID), nil
+	}
+}
+
+func (v *hmacVerifier) Authenticator() Authenticator {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (Principal, ServerError) {
+		keyID, err := v.Verify(ctx, request, rawHttpRequest)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrincipal(keyID, v.getScopes(keyID)), nil
+	}
+}
+
+func GetSigningKeyID(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(signatureKeyIDKey{}).(string)
+	return keyID, ok
+}
+
+func NewHMACVerifier(keys map[string][]byte, maxSkew time.Duration, nonces NonceStore) HMACVerifier {
+	if maxSkew <= 0 {
+		maxSkew = default_max_clock_skew
+	}
+	if nonces == nil {
+		nonces = NewMemoryNonceStore()
+	}
+	verifier := &hmacVerifier{
+		keys:    map[string][]byte{},
+		scopes:  map[string][]string{},
+		maxSkew: maxSkew,
+		nonces:  nonces,
+		now:     time.Now,
+	}
+	for k, v := range keys {
+		verifier.AddKey(k, v)
+	}
+	return verifier
+}
@@ -0,0 +1,163 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	hmac_test_key_id = "client-1"
+	hmac_test_body   = `{"jsonrpc":"2.0","id":"1","method":"transfer","params":{"amount":10}}`
+)
+
+var hmacTestKey = []byte("secret")
+
+type capturingTransport struct {
+	header string
+}
+
+func (t *capturingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.header = req.Header.Get(SignatureHeader)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func signTestRequest(t *testing.T, key []byte, signedAt time.Time) string {
+	captured := &capturingTransport{}
+	transport := NewHMACSigningTransport(captured, hmac_test_key_id, key).(*hmacSigningTransport)
+	transport.now = func() time.Time {
+		return signedAt
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://rpc/", strings.NewReader(hmac_test_body))
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	_, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Failed to sign request. Error: %v", err)
+	}
+	return captured.header
+}
+
+func newTestHMACVerifier(now time.Time) *hmacVerifier {
+	clock := func() time.Time {
+		return now
+	}
+	nonces := NewMemoryNonceStore().(*memoryNonceStore)
+	nonces.now = clock
+	verifier := NewHMACVerifier(map[string][]byte{
+		hmac_test_key_id: hmacTestKey,
+	}, time.Minute, nonces).(*hmacVerifier)
+	verifier.now = clock
+	return verifier
+}
+
+func verifyTestRequest(verifier HMACVerifier, method string, body string, signature string) (string, ServerError) {
+	req := httptest.NewRequest(http.MethodPost, "http://rpc/", strings.NewReader(body))
+	if len(signature) > 0 {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	return verifier.Verify(context.Background(), &RequestBase{Method: method}, req)
+}
+
+func TestHMACVerify(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cases := []struct {
+		name     string
+		key      []byte
+		signedAt time.Time
+		method   string
+		body     string
+		omit     bool
+		verified bool
+	}{
+		{name: "valid signature", key: hmacTestKey, signedAt: now, method: "transfer", body: hmac_test_body, verified: true},
+		{name: "signed within skew", key: hmacTestKey, signedAt: now.Add(-50 * time.Second), method: "transfer", body: hmac_test_body, verified: true},
+		{name: "missing signature", key: hmacTestKey, signedAt: now, method: "transfer", body: hmac_test_body, omit: true},
+		{name: "wrong key", key: []byte("other"), signedAt: now, method: "transfer", body: hmac_test_body},
+		{name: "tampered body", key: hmacTestKey, signedAt: now, method: "transfer", body: strings.Replace(hmac_test_body, "10", "1000", 1)},
+		{name: "tampered method", key: hmacTestKey, signedAt: now, method: "withdraw", body: hmac_test_body},
+		{name: "signed too long ago", key: hmacTestKey, signedAt: now.Add(-2 * time.Minute), method: "transfer", body: hmac_test_body},
+		{name: "signed in the future", key: hmacTestKey, signedAt: now.Add(2 * time.Minute), method: "transfer", body: hmac_test_body},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			signature := signTestRequest(t, c.key, c.signedAt)
+			if c.omit {
+				signature = ""
+			}
+			keyID, err := verifyTestRequest(newTestHMACVerifier(now), c.method, c.body, signature)
+			if !c.verified {
+				if err == nil || err.GetStatus() != http.StatusUnauthorized {
+					t.Fatalf("Expected unauthorized error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err.GetMessage())
+			}
+			if keyID != hmac_test_key_id {
+				t.Fatalf("Expected key ID %v, got %v.", hmac_test_key_id, keyID)
+			}
+		})
+	}
+}
+
+func TestHMACVerifyRejectsReplay(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	verifier := newTestHMACVerifier(now)
+	signature := signTestRequest(t, hmacTestKey, now)
+
+	_, err := verifyTestRequest(verifier, "transfer", hmac_test_body, signature)
+	if err != nil {
+		t.Fatalf("First request must be accepted. Error: %v", err.GetMessage())
+	}
+	_, err = verifyTestRequest(verifier, "transfer", hmac_test_body, signature)
+	if err == nil || err.GetStatus() != http.StatusUnauthorized {
+		t.Fatalf("Expected replayed request to be rejected, got: %v", err)
+	}
+	_, err = verifyTestRequest(verifier, "transfer", hmac_test_body, signTestRequest(t, hmacTestKey, now))
+	if err != nil {
+		t.Fatalf("Request with a fresh nonce must be accepted. Error: %v", err.GetMessage())
+	}
+}
+
+func TestMemoryNonceStoreExpiry(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	store := NewMemoryNonceStore().(*memoryNonceStore)
+	store.now = func() time.Time {
+		return now
+	}
+	if !store.Remember("nonce", now.Add(time.Minute)) {
+		t.Fatal("New nonce must be accepted.")
+	}
+	if store.Remember("nonce", now.Add(time.Minute)) {
+		t.Fatal("Seen nonce must be rejected.")
+	}
+	now = now.Add(2 * time.Minute)
+	if !store.Remember("nonce", now.Add(time.Minute)) {
+		t.Fatal("Expired nonce must be accepted again.")
+	}
+}
+
+func TestHMACAuthenticatorScopes(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	verifier := newTestHMACVerifier(now)
+	verifier.SetKeyScopes(hmac_test_key_id, []string{"transfer"})
+	req := httptest.NewRequest(http.MethodPost, "http://rpc/", strings.NewReader(hmac_test_body))
+	req.Header.Set(SignatureHeader, signTestRequest(t, hmacTestKey, now))
+	p, err := verifier.Authenticator()(context.Background(), &RequestBase{Method: "transfer"}, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err.GetMessage())
+	}
+	if p.GetID() != hmac_test_key_id || !p.HasScope("transfer") {
+		t.Fatalf("Unexpected principal: %v %v", p.GetID(), p.GetScopes())
+	}
+}
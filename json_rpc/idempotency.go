@@ -0,0 +1,279 @@
+package json_rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	default_idempotency_ttl  = 24 * time.Hour
+	max_idempotency_key_size = 255
+)
+
+type IdempotentResponse struct {
+	Fingerprint string          `json:"fingerprint"`
+	Headers     http.Header     `json:"headers,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Err         *Error          `json:"error,omitempty"`
+	Status      int             `json:"status,omitempty"`
+}
+
+type IdempotencyStore interface {
+	Acquire(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*IdempotentResponse, bool, error)
+	Complete(key string, response *IdempotentResponse, ttl time.Duration) error
+	Release(key string) error
+}
+
+type idempotencyEntry struct {
+	response  *IdempotentResponse
+	expiresAt time.Time
+	done      chan struct{}
+}
+
+type memoryIdempotencyStore struct {
+	lock    sync.Mutex
+	entries map[string]*idempotencyEntry
+	calls   int
+	now     func() time.Time
+}
+
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	for k, e := range s.entries {
+		if e.expiresAt.Before(now) {
+			s.remove(k, e)
+		}
+	}
+}
+
+func (s *memoryIdempotencyStore) remove(key string, e *idempotencyEntry) {
+	delete(s.entries, key)
+	if e.response == nil {
+		close(e.done)
+	}
+}
+
+func (s *memoryIdempotencyStore) Acquire(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*IdempotentResponse, bool, error) {
+	for {
+		s.lock.Lock()
+		now := s.now()
+		s.calls++
+		if s.calls%sweep_interval == 0 {
+			s.sweep(now)
+		}
+		e, ok := s.entries[key]
+		if ok && e.expiresAt.Before(now) {
+			s.remove(key, e)
+			ok = false
+		}
+		if !ok {
+			s.entries[key] = &idempotencyEntry{
+				expiresAt: now.Add(ttl),
+				done:      make(chan struct{}),
+			}
+			s.lock.Unlock()
+			return nil, true, nil
+		}
+		if e.response != nil {
+			s.lock.Unlock()
+			return e.response, false, nil
+		}
+		done := e.done
+		expiresIn := e.expiresAt.Sub(now)
+		s.lock.Unlock()
+		timer := time.NewTimer(expiresIn)
+		select {
+		case <-done:
+			timer.Stop()
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+func (s *memoryIdempotencyStore) Complete(key string, response *IdempotentResponse, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	expiresAt := s.now().Add(ttl)
+	e, ok := s.entries[key]
+	if !ok {
+		s.entries[key] = &idempotencyEntry{
+			response:  response,
+			expiresAt: expiresAt,
+			done:      make(chan struct{}),
+		}
+		return nil
+	}
+	if e.response == nil {
+		close(e.done)
+	}
+	e.response = response
+	e.expiresAt = expiresAt
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, ok := s.entries[key]
+	if !ok || e.response != nil {
+		return nil
+	}
+	s.remove(key, e)
+	return nil
+}
+
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		entries: map[string]*idempotencyEntry{},
+		now:     time.Now,
+	}
+}
+
+func MakeIdempotencyConflictError(httpStatus int, message string, err error) ServerError {
+	return MakeErrorWithHttpStatus(json_rpc_module, 11, httpStatus, message, err)
+}
+
+func fingerprintParams(params interface{}) string {
+	hash := sha256.Sum256([]byte(canonicalJSON(marshalRecorded(params))))
+	return hex.EncodeToString(hash[:])
+}
+
+func isCacheableResult(response *ResponseInfo, err ServerError) bool {
+	if err != nil {
+		status := err.GetStatus()
+		return status >= 400 && status < 500 && status != http.StatusTooManyRequests && status != status_client_closed_request
+	}
+	if response == nil {
+		return false
+	}
+	_, isStream := response.Data.(ResultStream)
+	return !isStream
+}
+
+func toIdempotentResponse(fingerprint string, response *ResponseInfo, err ServerError) (*IdempotentResponse, error) {
+	res := &IdempotentResponse{
+		Fingerprint: fingerprint,
+		Status:      http.StatusOK,
+	}
+	if err != nil {
+		res.Err = err.ToError()
+		res.Status = err.GetStatus()
+		return res, nil
+	}
+	res.Headers = response.Headers
+	if response.Data == nil {
+		return res, nil
+	}
+	data, marshalErr := json.Marshal(response.Data)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	res.Result = data
+	return res, nil
+}
+
+func replayIdempotentResponse(stored *IdempotentResponse) (*ResponseInfo, ServerError) {
+	headers := http.Header{}
+	applyHeaders(headers, stored.Headers)
+	headers.Set(IdempotentReplayedHeader, "true")
+	if stored.Err != nil {
+		var data error
+		if stored.Err.Data != nil {
+			data = errors.New(fmt.Sprint(stored.Err.Data))
+			text, ok := stored.Err.Data.(*string)
+			if ok && text != nil {
+				data = errors.New(*text)
+			}
+		}
+		return nil, WithErrorHeaders(&serverErrorImpl{
+			code:       stored.Err.Code,
+			message:    stored.Err.Message,
+			data:       data,
+			httpStatus: stored.Status,
+		}, headers)
+	}
+	return &ResponseInfo{
+		Headers: headers,
+		Data:    decodeRawJSON(stored.Result),
+	}, nil
+}
+
+type idempotentRpcHandlers struct {
+	RpcHandlers
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+func (r *idempotentRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	handler, ok := r.RpcHandlers.GetHandler(name)
+	if !ok || !handler.Idempotent {
+		return handler, ok
+	}
+	handle := handler.Handle
+	handler.Handle = func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		key := request.Headers.Get(IdempotencyKeyHeader)
+		if len(key) <= 0 {
+			return handle(ctx, request)
+		}
+		if len(key) > max_idempotency_key_size {
+			return nil, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusBadRequest, "Idempotency key is too long.", fmt.Errorf("Length: %v. Max: %v", len(key), max_idempotency_key_size))
+		}
+		scope := principalScope(ctx, name, key)
+		fingerprint := fingerprintParams(request.Data)
+		stored, acquired, err := r.store.Acquire(ctx, scope, fingerprint, r.ttl)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, MakeIdempotencyConflictError(http.StatusConflict, "Request with the same idempotency key is in progress.", err)
+			}
+			return nil, MakeError(json_rpc_module, 6, "Idempotency store failed.", err)
+		}
+		if !acquired {
+			if stored.Fingerprint != fingerprint {
+				return nil, MakeIdempotencyConflictError(http.StatusUnprocessableEntity, "Idempotency key was used with different parameters.", errors.New("Key: "+key))
+			}
+			return replayIdempotentResponse(stored)
+		}
+		completed := false
+		defer func() {
+			if !completed {
+				_ = r.store.Release(scope)
+			}
+		}()
+		response, responseErr := handle(ctx, request)
+		if !isCacheableResult(response, responseErr) {
+			return response, responseErr
+		}
+		record, err := toIdempotentResponse(fingerprint, response, responseErr)
+		if err != nil {
+			return response, responseErr
+		}
+		completed = r.store.Complete(scope, record, r.ttl) == nil
+		return response, responseErr
+	}
+	return handler, true
+}
+
+func NewIdempotentRpcHandlers(handlers RpcHandlers, store IdempotencyStore, ttl time.Duration) RpcHandlers {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+	if ttl <= 0 {
+		ttl = default_idempotency_ttl
+	}
+	return &idempotentRpcHandlers{
+		RpcHandlers: handlers,
+		store:       store,
+		ttl:         ttl,
+	}
+}
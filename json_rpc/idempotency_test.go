@@ -0,0 +1,152 @@
+package json_rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type idempotencyTestParams struct {
+	Value int `json:"value"`
+}
+
+type idempotencyTestResult struct {
+	Calls int32 `json:"calls"`
+}
+
+func newIdempotencyTestHandlers(handle RequestHandler) RpcHandlers {
+	return NewIdempotentRpcHandlers(NewDefaultRpcHandlers(map[string]HandlingInfo{
+		"create": {
+			Idempotent: true,
+			NewParams: func() interface{} {
+				return &idempotencyTestParams{}
+			},
+			Handle: handle,
+		},
+	}), NewMemoryIdempotencyStore(), time.Minute)
+}
+
+func newIdempotencyTestRequest(key string) *RequestInfo {
+	headers := http.Header{}
+	headers.Set(IdempotencyKeyHeader, key)
+	return &RequestInfo{
+		Headers: headers,
+		Data:    &idempotencyTestParams{Value: 1},
+	}
+}
+
+func TestIdempotencyConcurrentDuplicates(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	handlers := newIdempotencyTestHandlers(func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		n := atomic.AddInt32(&calls, 1)
+		<-release
+		return &ResponseInfo{
+			Data: &idempotencyTestResult{Calls: n},
+		}, nil
+	})
+	handler, ok := handlers.GetHandler("create")
+	if !ok {
+		t.Fatal("Handler is not registered.")
+	}
+
+	const duplicates = 8
+	var replayed int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < duplicates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			response, err := handler.Handle(ctx, newIdempotencyTestRequest("key-1"))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err.GetMessage())
+				return
+			}
+			if response.Headers.Get(IdempotentReplayedHeader) == "true" {
+				atomic.AddInt32(&replayed, 1)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("Expected handler to run once, ran %v times.", calls)
+	}
+	if replayed != duplicates-1 {
+		t.Fatalf("Expected %v replayed responses, got %v.", duplicates-1, replayed)
+	}
+}
+
+func TestIdempotencyReleaseAfterPanic(t *testing.T) {
+	var calls int32
+	handlers := newIdempotencyTestHandlers(func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			panic("handler failed")
+		}
+		return &ResponseInfo{
+			Data: &idempotencyTestResult{Calls: n},
+		}, nil
+	})
+	handler, _ := handlers.GetHandler("create")
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Expected handler to panic.")
+			}
+		}()
+		_, _ = handler.Handle(context.Background(), newIdempotencyTestRequest("key-2"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := handler.Handle(ctx, newIdempotencyTestRequest("key-2"))
+	if err != nil {
+		t.Fatalf("Expected retry after panic to run. Error: %v", err.GetMessage())
+	}
+	if response.Headers.Get(IdempotentReplayedHeader) == "true" {
+		t.Fatal("Response after panic must not be a replay.")
+	}
+	if calls != 2 {
+		t.Fatalf("Expected handler to run twice, ran %v times.", calls)
+	}
+}
+
+func TestIdempotencyReplayWithMsgPack(t *testing.T) {
+	var calls int32
+	handlers := newIdempotencyTestHandlers(func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		return &ResponseInfo{
+			Data: &idempotencyTestResult{Calls: atomic.AddInt32(&calls, 1)},
+		}, nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(CreateRpcHandlerWithCodecs(handlers, NewMsgPackCodec())))
+	defer server.Close()
+
+	client := NewClientWithCodec(server.Client(), NewMsgPackCodec())
+	headers := http.Header{}
+	headers.Set(IdempotencyKeyHeader, "key-3")
+	for i := 0; i < 2; i++ {
+		response, err := client.Call(server.URL, "create", RPCArguments{
+			Headers: headers.Clone(),
+			Data:    &idempotencyTestParams{Value: 1},
+		}, func() interface{} {
+			return &idempotencyTestResult{}
+		})
+		if err != nil {
+			t.Fatalf("Call %v failed. Error: %v", i, err)
+		}
+		result, ok := response.Result.(*idempotencyTestResult)
+		if !ok || result.Calls != 1 {
+			t.Fatalf("Call %v returned unexpected result: %+v", i, response.Result)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package json_rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	min_burst = 1
+)
+
+type RateLimitKeyFunc func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) string
+
+type RateLimitPolicy struct {
+	Rate  float64
+	Burst int
+	Key   RateLimitKeyFunc
+}
+
+type RateLimiter interface {
+	Take(key string, rate float64, burst int) (bool, time.Duration)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	rate    float64
+	burst   int
+	updated time.Time
+}
+
+type memoryRateLimiter struct {
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+	calls   int
+	now     func() time.Time
+}
+
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.rate >= float64(b.burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func (l *memoryRateLimiter) Take(key string, rate float64, burst int) (bool, time.Duration) {
+	if burst < min_burst {
+		burst = min_burst
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	l.calls++
+	if l.calls%sweep_interval == 0 {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{
+			tokens:  float64(burst),
+			updated: now,
+		}
+		l.buckets[key] = b
+	}
+	b.rate = rate
+	b.burst = burst
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+func KeyByRemoteIP(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) string {
+	host, _, err := net.SplitHostPort(rawHttpRequest.RemoteAddr)
+	if err != nil {
+		return rawHttpRequest.RemoteAddr
+	}
+	return host
+}
+
+func KeyByPrincipal(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) string {
+	p, ok := GetPrincipal(ctx)
+	if !ok {
+		return "anonymous@" + KeyByRemoteIP(ctx, request, rawHttpRequest)
+	}
+	return p.GetID()
+}
+
+func KeyByHeader(name string) RateLimitKeyFunc {
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) string {
+		return rawHttpRequest.Header.Get(name)
+	}
+}
+
+func MakeRateLimitedError(retryAfter time.Duration, err error) ServerError {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return WithErrorHeaders(MakeErrorWithHttpStatus(json_rpc_module, 9, http.StatusTooManyRequests, "Rate limit exceeded.", err), http.Header{
+		"Retry-After": []string{strconv.FormatInt(seconds, 10)},
+	})
+}
+
+func NewRateLimitContextBuilder(limiter RateLimiter, method string, policy RateLimitPolicy) ContextBuilder {
+	key := policy.Key
+	if key == nil {
+		key = KeyByRemoteIP
+	}
+	burst := policy.Burst
+	if burst < min_burst {
+		burst = min_burst
+	}
+	return func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+		identity := key(ctx, request, rawHttpRequest)
+		allowed, retryAfter := limiter.Take(method+"\n"+identity, policy.Rate, burst)
+		if allowed {
+			return ctx, nil
+		}
+		return ctx, MakeRateLimitedError(retryAfter, fmt.Errorf("Method: %v. Key: %v", method, identity))
+	}
+}
+
+type rateLimitedRpcHandlers struct {
+	RpcHandlers
+	limiter       RateLimiter
+	policies      map[string]RateLimitPolicy
+	defaultPolicy *RateLimitPolicy
+	lock          sync.Mutex
+	builders      map[string]ContextBuilder
+}
+
+func (r *rateLimitedRpcHandlers) getBuilder(name string) (ContextBuilder, bool) {
+	policy, ok := r.policies[name]
+	if !ok {
+		if r.defaultPolicy == nil {
+			return nil, false
+		}
+		policy = *r.defaultPolicy
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	builder, ok := r.builders[name]
+	if ok {
+		return builder, true
+	}
+	builder = NewRateLimitContextBuilder(r.limiter, name, policy)
+	r.builders[name] = builder
+	return builder, true
+}
+
+func (r *rateLimitedRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	handler, ok := r.RpcHandlers.GetHandler(name)
+	if !ok {
+		return handler, false
+	}
+	limit, ok := r.getBuilder(name)
+	if !ok {
+		return handler, true
+	}
+	handler = withDefaults(handler)
+	handler.ComposeContext = NewCompositeContextBuilder([]ContextBuilder{limit, handler.ComposeContext})
+	return handler, true
+}
+
+func NewRateLimitedRpcHandlers(handlers RpcHandlers, limiter RateLimiter, policies map[string]RateLimitPolicy, defaultPolicy *RateLimitPolicy) RpcHandlers {
+	if limiter == nil {
+		limiter = NewMemoryRateLimiter()
+	}
+	return &rateLimitedRpcHandlers{
+		RpcHandlers:   handlers,
+		limiter:       limiter,
+		policies:      policies,
+		defaultPolicy: defaultPolicy,
+		builders:      map[string]ContextBuilder{},
+	}
+}
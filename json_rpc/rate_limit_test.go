@@ -0,0 +1,42 @@
+package json_rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimiterBurst(t *testing.T) {
+	cases := []struct {
+		name    string
+		burst   int
+		allowed int
+	}{
+		{name: "zero burst allows one request", burst: 0, allowed: 1},
+		{name: "negative burst allows one request", burst: -5, allowed: 1},
+		{name: "burst of three", burst: 3, allowed: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			now := time.Unix(1600000000, 0)
+			limiter := NewMemoryRateLimiter().(*memoryRateLimiter)
+			limiter.now = func() time.Time {
+				return now
+			}
+			allowed := 0
+			for i := 0; i < c.allowed+2; i++ {
+				ok, _ := limiter.Take("key", 1, c.burst)
+				if ok {
+					allowed++
+				}
+			}
+			if allowed != c.allowed {
+				t.Fatalf("Expected %v allowed requests, got %v.", c.allowed, allowed)
+			}
+			now = now.Add(time.Second)
+			ok, _ := limiter.Take("key", 1, c.burst)
+			if !ok {
+				t.Fatal("Expected a request to be allowed after refill.")
+			}
+		})
+	}
+}
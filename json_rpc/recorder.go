@@ -0,0 +1,143 @@
+package json_rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+type CallRecord struct {
+	Time       time.Time       `json:"time"`
+	Duration   time.Duration   `json:"duration"`
+	URL        string          `json:"url,omitempty"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Err        *Error          `json:"error,omitempty"`
+	HttpStatus int             `json:"http_status,omitempty"`
+}
+
+type Recorder interface {
+	Record(record *CallRecord) custom_error.CustomError
+	Close() custom_error.CustomError
+}
+
+type writerRecorder struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+func (r *writerRecorder) Record(record *CallRecord) custom_error.CustomError {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	err := r.encoder.Encode(record)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to write call record. Method: '%v'. Error: %v", record.Method, err)
+	}
+	return nil
+}
+
+func (r *writerRecorder) Close() custom_error.CustomError {
+	if r.closer == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	err := r.closer.Close()
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to close recorder. Error: %v", err)
+	}
+	return nil
+}
+
+func NewWriterRecorder(w io.Writer) Recorder {
+	return &writerRecorder{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func NewFileRecorder(path string) (Recorder, custom_error.CustomError) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to open record file '%v'. Error: %v", path, err)
+	}
+	return &writerRecorder{
+		encoder: json.NewEncoder(f),
+		closer:  f,
+	}, nil
+}
+
+func ReadCallRecords(r io.Reader) ([]*CallRecord, custom_error.CustomError) {
+	records := []*CallRecord{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) <= 0 {
+			continue
+		}
+		record := &CallRecord{}
+		err := json.Unmarshal(data, record)
+		if err != nil {
+			return nil, custom_error.MakeErrorf("Failed to parse call record at line %v. Error: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to read call records. Error: %v", err)
+	}
+	return records, nil
+}
+
+func LoadCallRecords(path string) ([]*CallRecord, custom_error.CustomError) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, custom_error.MakeErrorf("Failed to open record file '%v'. Error: %v", path, err)
+	}
+	defer f.Close()
+	records, cErr := ReadCallRecords(f)
+	if cErr != nil {
+		return nil, custom_error.WrapErrorf(cErr, "Failed to load records from '%v'.", path)
+	}
+	return records, nil
+}
+
+func marshalRecorded(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func canonicalJSON(data json.RawMessage) string {
+	if len(data) <= 0 {
+		return ""
+	}
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return string(data)
+	}
+	if v == nil {
+		return ""
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return string(data)
+	}
+	return string(canonical)
+}
@@ -0,0 +1,133 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/logs"
+)
+
+type recordingRpcHandlers struct {
+	handlers RpcHandlers
+	recorder Recorder
+}
+
+func (r *recordingRpcHandlers) record(ctx context.Context, record *CallRecord) {
+	cErr := r.recorder.Record(record)
+	if cErr != nil {
+		logs.GetLogger(ctx).Errorf("Failed to record json-rpc call. Error: %v", cErr)
+	}
+}
+
+func (r *recordingRpcHandlers) wrap(method string, handle RequestHandler) RequestHandler {
+	return func(ctx context.Context, request *RequestInfo) (response *ResponseInfo, resErr ServerError) {
+		record := &CallRecord{
+			Time:   time.Now(),
+			Method: method,
+			Params: marshalRecorded(request.Data),
+		}
+		defer func() {
+			record.Duration = time.Since(record.Time)
+			v := recover()
+			if v != nil {
+				serverError, ok := v.(ServerError)
+				if ok {
+					record.Err = serverError.ToError()
+					record.HttpStatus = serverError.GetStatus()
+				} else {
+					record.Err = &Error{
+						Message: "Unknown error",
+					}
+					record.HttpStatus = http.StatusInternalServerError
+				}
+				r.record(ctx, record)
+				panic(v)
+			}
+			if resErr != nil {
+				record.Err = resErr.ToError()
+				record.HttpStatus = resErr.GetStatus()
+			} else {
+				record.HttpStatus = http.StatusOK
+				if response != nil {
+					record.Result = marshalRecorded(response.Data)
+				}
+			}
+			r.record(ctx, record)
+		}()
+		return handle(ctx, request)
+	}
+}
+
+func (r *recordingRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	handler, ok := r.handlers.GetHandler(name)
+	if !ok {
+		return handler, ok
+	}
+	handler.Handle = r.wrap(name, handler.Handle)
+	return handler, true
+}
+
+func (r *recordingRpcHandlers) GetHeaders(ctx context.Context) http.Header {
+	return r.handlers.GetHeaders(ctx)
+}
+
+func (r *recordingRpcHandlers) GetDecoder(data io.Reader) *json.Decoder {
+	return r.handlers.GetDecoder(data)
+}
+
+func (r *recordingRpcHandlers) NewContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return r.handlers.NewContext(ctx)
+}
+
+func NewRecordingRpcHandlers(handlers RpcHandlers, recorder Recorder) RpcHandlers {
+	return &recordingRpcHandlers{
+		handlers: handlers,
+		recorder: recorder,
+	}
+}
+
+type recordingClient struct {
+	client   Client
+	recorder Recorder
+}
+
+func (c *recordingClient) Call(url string, method string, args RPCArguments, expectedResult ResponseResultFactory) (*UntypedResponse, custom_error.CustomError) {
+	record := &CallRecord{
+		Time:   time.Now(),
+		URL:    url,
+		Method: method,
+		Params: marshalRecorded(args.Data),
+	}
+	response, err := c.client.Call(url, method, args, expectedResult)
+	record.Duration = time.Since(record.Time)
+	if err != nil {
+		serverError, ok := err.(ServerError)
+		if ok {
+			record.Err = serverError.ToError()
+			record.HttpStatus = serverError.GetStatus()
+		} else {
+			record.Err = &Error{
+				Message: err.Error(),
+			}
+		}
+	} else if response != nil {
+		record.HttpStatus = http.StatusOK
+		record.Result = marshalRecorded(response.Result)
+	}
+	cErr := c.recorder.Record(record)
+	if cErr != nil {
+		logs.GetLogger(nil).Errorf("Failed to record json-rpc call. Error: %v", cErr)
+	}
+	return response, err
+}
+
+func NewRecordingClient(client Client, recorder Recorder) Client {
+	return &recordingClient{
+		client:   client,
+		recorder: recorder,
+	}
+}
@@ -0,0 +1,152 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	max_body_description = 512
+)
+
+type RemoteError interface {
+	ServerError
+	GetModule() int
+	GetErrorCode() int
+	GetRawData() json.RawMessage
+}
+
+type remoteErrorEnvelope struct {
+	Code    int64           `json:"code,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type remoteErrorResponse struct {
+	Version string               `json:"jsonrpc"`
+	ID      string               `json:"id,omitempty"`
+	Err     *remoteErrorEnvelope `json:"error,omitempty"`
+}
+
+type remoteError struct {
+	custom_error.CustomError
+	code       int64
+	message    string
+	data       json.RawMessage
+	httpStatus int
+}
+
+func (e *remoteError) GetCode() int64 {
+	return e.code
+}
+
+func (e *remoteError) GetModule() int {
+	return GetErrorModule(e.code)
+}
+
+func (e *remoteError) GetErrorCode() int {
+	return GetModuleErrorCode(e.code)
+}
+
+func (e *remoteError) GetMessage() string {
+	return e.message
+}
+
+func (e *remoteError) GetData() *string {
+	if len(e.data) <= 0 {
+		return nil
+	}
+	var v string
+	err := json.Unmarshal(e.data, &v)
+	if err != nil {
+		v = string(e.data)
+	}
+	return &v
+}
+
+func (e *remoteError) GetRawData() json.RawMessage {
+	return e.data
+}
+
+func (e *remoteError) GetStatus() int {
+	return e.httpStatus
+}
+
+func (e *remoteError) ToError() *Error {
+	return &Error{
+		Code:    e.code,
+		Message: e.message,
+		Data:    decodeRawJSON(e.data),
+	}
+}
+
+type httpStatusError struct {
+	custom_error.CustomError
+	httpStatus int
+}
+
+func (e *httpStatusError) GetStatus() int {
+	return e.httpStatus
+}
+
+func newHttpStatusError(httpStatus int, status string, data []byte) *httpStatusError {
+	return &httpStatusError{
+		CustomError: custom_error.MakeErrorf("%v: %v. Body: %v", httpStatus, status, describeBody(data)),
+		httpStatus:  httpStatus,
+	}
+}
+
+func newRemoteError(envelope *remoteErrorEnvelope, httpStatus int) *remoteError {
+	return &remoteError{
+		CustomError: custom_error.MakeErrorf("Server returned error %v. HTTP status: %v. Message: %v. Data: %s", DescribeErrorCode(envelope.Code), httpStatus, envelope.Message, envelope.Data),
+		code:        envelope.Code,
+		message:     envelope.Message,
+		data:        envelope.Data,
+		httpStatus:  httpStatus,
+	}
+}
+
+func parseRemoteError(data []byte, httpStatus int) (*remoteError, bool) {
+	response := remoteErrorResponse{}
+	err := json.Unmarshal(data, &response)
+	if err != nil || response.Err == nil {
+		return nil, false
+	}
+	return newRemoteError(response.Err, httpStatus), true
+}
+
+type genericErrorEnvelope struct {
+	Code    int64       `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type genericErrorResponse struct {
+	Err *genericErrorEnvelope `json:"error,omitempty"`
+}
+
+func parseRemoteErrorWithCodec(codec Codec, data []byte, httpStatus int) (*remoteError, bool) {
+	if isJSONCodec(codec) {
+		return parseRemoteError(data, httpStatus)
+	}
+	response := genericErrorResponse{}
+	err := codec.NewDecoder(bytes.NewReader(data)).Decode(&response)
+	if err != nil || response.Err == nil {
+		return nil, false
+	}
+	return newRemoteError(&remoteErrorEnvelope{
+		Code:    response.Err.Code,
+		Message: response.Err.Message,
+		Data:    marshalRecorded(response.Err.Data),
+	}, httpStatus), true
+}
+
+func describeBody(data []byte) string {
+	if len(data) > max_body_description {
+		return fmt.Sprintf("%s...", data[:max_body_description])
+	}
+	return string(data)
+}
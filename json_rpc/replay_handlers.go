@@ -0,0 +1,112 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type ReplayMatcher func(record *CallRecord, method string, params json.RawMessage) bool
+
+type replayRpcHandlers struct {
+	lock    sync.Mutex
+	records []*CallRecord
+	matches ReplayMatcher
+	served  map[*CallRecord]int
+}
+
+func (r *replayRpcHandlers) pick(method string, params json.RawMessage) (*CallRecord, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var picked *CallRecord
+	for i := range r.records {
+		record := r.records[i]
+		if !r.matches(record, method, params) {
+			continue
+		}
+		if picked == nil || r.served[record] < r.served[picked] {
+			picked = record
+		}
+	}
+	if picked == nil {
+		return nil, false
+	}
+	r.served[picked]++
+	return picked, true
+}
+
+func (r *replayRpcHandlers) handle(method string) RequestHandler {
+	return func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		var params json.RawMessage
+		raw, ok := request.Data.(*rawParams)
+		if ok {
+			params, _ = raw.toJSON()
+		}
+		record, ok := r.pick(method, params)
+		if !ok {
+			return nil, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusNotFound, "No recorded response for method: "+method, errors.New("Params: "+string(params)))
+		}
+		if record.Err != nil {
+			return nil, newReplayedError(record)
+		}
+		return &ResponseInfo{
+			Data: decodeRawJSON(record.Result),
+		}, nil
+	}
+}
+
+func (r *replayRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	return HandlingInfo{
+		Handle: r.handle(name),
+		NewParams: func() interface{} {
+			return &rawParams{}
+		},
+		ComposeContext: dummyContextFactory,
+		GetHeaders:     dummyContextExpert,
+	}, true
+}
+
+func (r *replayRpcHandlers) GetHeaders(ctx context.Context) http.Header {
+	return dummyHeaders(ctx)
+}
+
+func (r *replayRpcHandlers) GetDecoder(data io.Reader) *json.Decoder {
+	return defaultDecoder(data)
+}
+
+func (r *replayRpcHandlers) NewContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return defaultCtxFactory(ctx)
+}
+
+func newReplayedError(record *CallRecord) ServerError {
+	status := record.HttpStatus
+	if status <= 0 {
+		status = http.StatusInternalServerError
+	}
+	return newRemoteError(&remoteErrorEnvelope{
+		Code:    record.Err.Code,
+		Message: record.Err.Message,
+		Data:    marshalRecorded(record.Err.Data),
+	}, status)
+}
+
+func MatchMethodAndParams(record *CallRecord, method string, params json.RawMessage) bool {
+	if record.Method != method {
+		return false
+	}
+	return canonicalJSON(record.Params) == canonicalJSON(params)
+}
+
+func NewReplayRpcHandlers(records []*CallRecord, matches ReplayMatcher) RpcHandlers {
+	if matches == nil {
+		matches = MatchMethodAndParams
+	}
+	return &replayRpcHandlers{
+		records: records,
+		matches: matches,
+		served:  map[*CallRecord]int{},
+	}
+}
@@ -39,6 +39,11 @@ type HandlingInfo struct {
 	NewParams      RequestParamsFactory
 	ComposeContext ContextBuilder
 	GetHeaders     HeadersFromContext
+	Authenticate   Authenticator
+	RequiredScopes []string
+	Idempotent     bool
+	Cache          *CachePolicy
+	AllowGet       bool
 }
 
 type UnknownErrorData struct {
@@ -70,8 +75,16 @@ func NewJsonRPCHandle(getHandler func(name string) (HandlingInfo, bool), getDeco
 		if err != nil {
 			ThrowError(0, 0, "Failed to read request body.", err)
 		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		newDecoder := func(data io.Reader) Decoder {
+			return getDecoder(data)
+		}
+		codec, ok := GetRequestCodec(srcCtx)
+		if ok {
+			newDecoder = codec.NewDecoder
+		}
 		incomingRequest := RequestBase{}
-		dec := getDecoder(bytes.NewReader(data))
+		dec := newDecoder(bytes.NewReader(data))
 		err = dec.Decode(&incomingRequest)
 		if err != nil {
 			ThrowError(0, 1, "Failed to parse request body.", err)
@@ -87,7 +100,11 @@ func NewJsonRPCHandle(getHandler func(name string) (HandlingInfo, bool), getDeco
 		}
 
 		resHeaders := http.Header{}
-		ctx, composeErr := handler.ComposeContext(srcCtx, &incomingRequest, r)
+		ctx, authErr := authorize(srcCtx, handler, &incomingRequest, r)
+		if authErr != nil {
+			panic(authErr)
+		}
+		ctx, composeErr := handler.ComposeContext(ctx, &incomingRequest, r)
 		if ctx != nil {
 			applyHeaders(resHeaders, handler.GetHeaders(ctx))
 		}
@@ -98,7 +115,7 @@ func NewJsonRPCHandle(getHandler func(name string) (HandlingInfo, bool), getDeco
 		params := RequestParams{
 			Params: handler.NewParams(),
 		}
-		dec = getDecoder(bytes.NewReader(data))
+		dec = newDecoder(bytes.NewReader(data))
 		err = dec.Decode(&params)
 		if err != nil {
 			ThrowError(0, 3, "Failed to prepare arguments for handler,", err)
@@ -125,6 +142,11 @@ func NewRawHandle(methodHandler HandlingInfo, parse RawRequestParser) RawRequest
 		ComposeContext: methodHandler.ComposeContext,
 		GetHeaders:     methodHandler.GetHeaders,
 		NewParams:      methodHandler.NewParams,
+		Authenticate:   methodHandler.Authenticate,
+		RequiredScopes: methodHandler.RequiredScopes,
+		Idempotent:     methodHandler.Idempotent,
+		Cache:          methodHandler.Cache,
+		AllowGet:       methodHandler.AllowGet,
 	}
 	if handler.ComposeContext == nil {
 		handler.ComposeContext = dummyContextFactory
@@ -140,6 +162,10 @@ func NewRawHandle(methodHandler HandlingInfo, parse RawRequestParser) RawRequest
 		if cErr != nil {
 			ThrowError(0, 1, "Failed to parse request.", cErr)
 		}
+		ctx, authErr := authorize(ctx, handler, incomingRequest, r)
+		if authErr != nil {
+			panic(authErr)
+		}
 		ctx, composeErr := handler.ComposeContext(ctx, incomingRequest, r)
 		if ctx != nil {
 			applyHeaders(resHeaders, handler.GetHeaders(ctx))
@@ -163,6 +189,10 @@ func NewRawHandle(methodHandler HandlingInfo, parse RawRequestParser) RawRequest
 }
 
 func CreateRawHandler(newContext InitialContextFactory, handle RawRequestHandler, defaultHeaders HeadersFromContext) func(w http.ResponseWriter, r *http.Request) {
+	return CreateCodecRawHandler(newContext, handle, defaultHeaders, nil)
+}
+
+func CreateCodecRawHandler(newContext InitialContextFactory, handle RawRequestHandler, defaultHeaders HeadersFromContext, codecs Codecs) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := newContext(r.Context())
 		defer cancel()
@@ -171,6 +201,25 @@ func CreateRawHandler(newContext InitialContextFactory, handle RawRequestHandler
 		if r.Body != nil {
 			defer r.Body.Close()
 		}
+		var responseCodec Codec = NewJSONCodec(nil)
+		supportedContentType := true
+		if codecs != nil {
+			var requestCodec Codec
+			requestCodec, supportedContentType = codecs.ForContentType(r.Header.Get("Content-Type"))
+			if !supportedContentType {
+				requestCodec = codecs.GetDefault()
+			}
+			accepted, ok := codecs.ForAccept(r.Header.Get("Accept"))
+			if ok {
+				responseCodec = accepted
+			} else {
+				responseCodec = requestCodec
+			}
+			ctx = withNegotiatedCodecs(ctx, requestCodec, responseCodec)
+			if len(w.Header().Get("Content-Type")) <= 0 {
+				w.Header().Set("Content-Type", responseCodec.GetContentType())
+			}
+		}
 		var rid string
 		defer func() {
 			v := recover()
@@ -193,10 +242,14 @@ func CreateRawHandler(newContext InitialContextFactory, handle RawRequestHandler
 			} else {
 				rpcError.Err = serverError.ToError()
 				httpStatus = serverError.GetStatus()
+				withHeaders, ok := serverError.(ServerErrorHeaders)
+				if ok {
+					applyHeaders(w.Header(), withHeaders.GetHeaders())
+				}
 			}
 
 			w.WriteHeader(httpStatus)
-			err := json.NewEncoder(w).Encode(rpcError)
+			err := responseCodec.NewEncoder(w).Encode(rpcError)
 			if err == nil {
 				return
 			}
@@ -208,13 +261,31 @@ func CreateRawHandler(newContext InitialContextFactory, handle RawRequestHandler
 			}
 		}()
 
+		if !supportedContentType {
+			panic(MakeUnsupportedMediaTypeError("Unsupported content type.", errors.New(r.Header.Get("Content-Type"))))
+		}
 		result, rid := handle(ctx, r)
 		if result == nil {
 			ThrowError(0, 5, "Empty response from handler.", errors.New("Empty response from handler"))
 		}
 
 		applyHeaders(w.Header(), result.Headers)
-		err := json.NewEncoder(w).Encode(UntypedResponse{
+		_, notModified := result.Data.(notModifiedResult)
+		if notModified {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		stream, ok := result.Data.(ResultStream)
+		if ok {
+			defer stream.Close()
+			if isJSONCodec(responseCodec) {
+				writeStreamResponse(ctx, w, rid, stream)
+				return
+			}
+			result.Data = collectStream(ctx, stream)
+		}
+		err := responseCodec.NewEncoder(w).Encode(UntypedResponse{
 			ResponseBase: ResponseBase{
 				Version: JSON_RPC_VERSION,
 				ID:      rid,
@@ -234,6 +305,11 @@ func CreateJSONRpcHandlerCustomUnmarshal(handlers RpcHandlers) func(w http.Respo
 	return CreateRawHandler(handlers.NewContext, handle, handlers.GetHeaders)
 }
 
+func CreateRpcHandlerWithCodecs(handlers RpcHandlers, codecs ...Codec) func(w http.ResponseWriter, r *http.Request) {
+	handle := NewJsonRPCHandle(handlers.GetHandler, handlers.GetDecoder)
+	return CreateCodecRawHandler(handlers.NewContext, handle, handlers.GetHeaders, NewCodecs(NewJSONCodec(handlers.GetDecoder), codecs...))
+}
+
 func defaultDecoder(data io.Reader) *json.Decoder {
 	return json.NewDecoder(data)
 }
@@ -291,17 +367,20 @@ func NewRpcHandlers(handlers map[string]HandlingInfo, defaultHeaders HeadersFrom
 	return NewCustomRpcHandlers(handlers, defaultHeaders, ctxFactory, defaultDecoder)
 }
 
+func withDefaults(handler HandlingInfo) HandlingInfo {
+	if handler.ComposeContext == nil {
+		handler.ComposeContext = dummyContextFactory
+	}
+	if handler.GetHeaders == nil {
+		handler.GetHeaders = dummyContextExpert
+	}
+	return handler
+}
+
 func NewCustomRpcHandlers(handlers map[string]HandlingInfo, defaultHeaders HeadersFromContext, contextFactory ContextFactory, decoderFactory DecoderFactory) RpcHandlers {
 	methodHandlers := map[string]HandlingInfo{}
 	for k, v := range handlers {
-		updated := v
-		if updated.ComposeContext == nil {
-			updated.ComposeContext = dummyContextFactory
-		}
-		if updated.GetHeaders == nil {
-			updated.GetHeaders = dummyContextExpert
-		}
-		methodHandlers[k] = updated
+		methodHandlers[k] = withDefaults(v)
 	}
 	return &rpcHandlers{
 		defaultHeaders: defaultHeaders,
@@ -0,0 +1,42 @@
+package json_rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnsupportedMediaType(t *testing.T) {
+	handle := NewCompressionHandler(CreateRpcHandlerWithCodecs(NewDefaultRpcHandlers(map[string]HandlingInfo{}), NewJSONCodec(nil)), CompressionConfig{})
+	cases := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "content type", headers: map[string]string{"Content-Type": "text/xml"}, expected: "Unsupported content type."},
+		{name: "content encoding", headers: map[string]string{"Content-Type": ContentTypeJSON, "Content-Encoding": "br"}, expected: "Unsupported content encoding."},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","id":"1","method":"test"}`))
+			for k, v := range c.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handle(w, req)
+			if w.Code != http.StatusUnsupportedMediaType {
+				t.Fatalf("Expected status %v, got %v.", http.StatusUnsupportedMediaType, w.Code)
+			}
+			response := remoteErrorResponse{}
+			err := json.NewDecoder(w.Body).Decode(&response)
+			if err != nil || response.Err == nil {
+				t.Fatalf("Failed to decode error response. Error: %v", err)
+			}
+			if response.Err.Code != MakeErrorCode(json_rpc_module, 12) || response.Err.Message != c.expected {
+				t.Fatalf("Unexpected error: %v %v", DescribeErrorCode(response.Err.Code), response.Err.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package json_rpc
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	default_cache_entries = 1024
+	etag_hash_size        = 16
+)
+
+type CacheKeyFunc func(ctx context.Context, method string, params interface{}) string
+
+type CachePolicy struct {
+	TTL    time.Duration
+	Key    CacheKeyFunc
+	Shared bool
+}
+
+type CachedResult struct {
+	Data      interface{}
+	Headers   http.Header
+	ETag      string
+	ExpiresAt time.Time
+}
+
+type ResultCache interface {
+	Get(key string) (*CachedResult, bool)
+	Set(key string, result *CachedResult)
+	Remove(key string)
+}
+
+type lruEntry struct {
+	key    string
+	result *CachedResult
+}
+
+type lruResultCache struct {
+	lock       sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+func (c *lruResultCache) Get(key string) (*CachedResult, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).result, true
+}
+
+func (c *lruResultCache) Set(key string, result *CachedResult) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if ok {
+		e.Value.(*lruEntry).result = result
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{
+		key:    key,
+		result: result,
+	})
+	for c.order.Len() > c.maxEntries {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruResultCache) Remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(e)
+	delete(c.entries, key)
+}
+
+func NewLRUResultCache(maxEntries int) ResultCache {
+	if maxEntries <= 0 {
+		maxEntries = default_cache_entries
+	}
+	return &lruResultCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func DefaultCacheKey(ctx context.Context, method string, params interface{}) string {
+	raw, ok := params.(json.RawMessage)
+	if !ok {
+		raw = marshalRecorded(params)
+	}
+	return method + "\n" + canonicalJSON(raw)
+}
+
+func computeETag(data interface{}) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(encoded)
+	return `W/"` + hex.EncodeToString(hash[:etag_hash_size]) + `"`, nil
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if len(ifNoneMatch) <= 0 || len(etag) <= 0 {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func cacheControl(private bool, maxAge time.Duration) string {
+	scope := "public"
+	if private {
+		scope = "private"
+	}
+	seconds := int64(math.Ceil(maxAge.Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+	return fmt.Sprintf("%v, max-age=%v", scope, seconds)
+}
+
+type notModifiedResult struct{}
+
+type cachingRpcHandlers struct {
+	RpcHandlers
+	cache ResultCache
+	now   func() time.Time
+}
+
+func (r *cachingRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	handler, ok := r.RpcHandlers.GetHandler(name)
+	if !ok || handler.Cache == nil || handler.Cache.TTL <= 0 {
+		return handler, ok
+	}
+	policy := *handler.Cache
+	if policy.Key == nil {
+		policy.Key = DefaultCacheKey
+	}
+	handle := handler.Handle
+	handler.Handle = func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		key := policy.Key(ctx, name, request.Data)
+		if !policy.Shared {
+			key = principalScope(ctx, name, key)
+		}
+		now := r.now()
+		entry, ok := r.cache.Get(key)
+		if !ok || !now.Before(entry.ExpiresAt) {
+			response, err := handle(ctx, request)
+			if err != nil || response == nil {
+				return response, err
+			}
+			_, isStream := response.Data.(ResultStream)
+			if isStream {
+				return response, nil
+			}
+			etag, marshalErr := computeETag(response.Data)
+			if marshalErr != nil {
+				return response, nil
+			}
+			entry = &CachedResult{
+				Data:      response.Data,
+				Headers:   response.Headers,
+				ETag:      etag,
+				ExpiresAt: now.Add(policy.TTL),
+			}
+			r.cache.Set(key, entry)
+		}
+		headers := http.Header{}
+		applyHeaders(headers, entry.Headers)
+		headers.Set("Cache-Control", cacheControl(!policy.Shared, entry.ExpiresAt.Sub(now)))
+		headers.Set("ETag", entry.ETag)
+		if etagMatches(request.Headers.Get("If-None-Match"), entry.ETag) {
+			return &ResponseInfo{
+				Headers: headers,
+				Data:    notModifiedResult{},
+			}, nil
+		}
+		return &ResponseInfo{
+			Headers: headers,
+			Data:    entry.Data,
+		}, nil
+	}
+	return handler, true
+}
+
+func NewCachingRpcHandlers(handlers RpcHandlers, cache ResultCache) RpcHandlers {
+	if cache == nil {
+		cache = NewLRUResultCache(default_cache_entries)
+	}
+	return &cachingRpcHandlers{
+		RpcHandlers: handlers,
+		cache:       cache,
+		now:         time.Now,
+	}
+}
@@ -0,0 +1,165 @@
+package json_rpc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+type RpcRouter interface {
+	Mount(prefix string, handlers RpcHandlers) custom_error.CustomError
+	Resolve(method string) (RpcHandlers, bool)
+	GetHandler(name string) (HandlingInfo, bool)
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type prefixedRpcHandlers struct {
+	RpcHandlers
+	prefix string
+}
+
+func (p *prefixedRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	if !strings.HasPrefix(name, p.prefix) {
+		return HandlingInfo{}, false
+	}
+	return p.RpcHandlers.GetHandler(name[len(p.prefix):])
+}
+
+type mountPoint struct {
+	prefix   string
+	handlers RpcHandlers
+	serve    func(w http.ResponseWriter, r *http.Request)
+}
+
+type rpcRouter struct {
+	lock     sync.RWMutex
+	mounts   []*mountPoint
+	codecs   Codecs
+	newServe func(handlers RpcHandlers) func(w http.ResponseWriter, r *http.Request)
+	fallback func(w http.ResponseWriter, r *http.Request)
+}
+
+func (r *rpcRouter) Mount(prefix string, handlers RpcHandlers) custom_error.CustomError {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i := range r.mounts {
+		if r.mounts[i].prefix == prefix {
+			return custom_error.MakeErrorf("Prefix '%v' is already mounted.", prefix)
+		}
+	}
+	prefixed := &prefixedRpcHandlers{
+		RpcHandlers: handlers,
+		prefix:      prefix,
+	}
+	mounts := append(append([]*mountPoint{}, r.mounts...), &mountPoint{
+		prefix:   prefix,
+		handlers: prefixed,
+		serve:    NewGetRequestAdapter(r.newServe(prefixed), prefixed),
+	})
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return len(mounts[i].prefix) > len(mounts[j].prefix)
+	})
+	r.mounts = mounts
+	return nil
+}
+
+func (r *rpcRouter) resolve(method string) (*mountPoint, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for i := range r.mounts {
+		if !strings.HasPrefix(method, r.mounts[i].prefix) {
+			continue
+		}
+		_, ok := r.mounts[i].handlers.GetHandler(method)
+		if ok {
+			return r.mounts[i], true
+		}
+	}
+	return nil, false
+}
+
+func (r *rpcRouter) Resolve(method string) (RpcHandlers, bool) {
+	mount, ok := r.resolve(method)
+	if !ok {
+		return nil, false
+	}
+	return mount.handlers, true
+}
+
+func (r *rpcRouter) GetHandler(name string) (HandlingInfo, bool) {
+	mount, ok := r.resolve(name)
+	if !ok {
+		return HandlingInfo{}, false
+	}
+	return mount.handlers.GetHandler(name)
+}
+
+func (r *rpcRouter) readMethod(req *http.Request) (string, bool) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return req.URL.Query().Get("method"), true
+	}
+	if req.Body == nil {
+		return "", false
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	codec := NewJSONCodec(nil)
+	if r.codecs != nil {
+		var ok bool
+		codec, ok = r.codecs.ForContentType(req.Header.Get("Content-Type"))
+		if !ok {
+			return "", false
+		}
+	}
+	incomingRequest := RequestBase{}
+	err = codec.NewDecoder(bytes.NewReader(data)).Decode(&incomingRequest)
+	if err != nil {
+		return "", false
+	}
+	return incomingRequest.Method, true
+}
+
+func (r *rpcRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	method, ok := r.readMethod(req)
+	if !ok {
+		r.fallback(w, req)
+		return
+	}
+	mount, ok := r.resolve(method)
+	if !ok {
+		r.fallback(w, req)
+		return
+	}
+	mount.serve(w, req)
+}
+
+func NewRpcRouter() RpcRouter {
+	return &rpcRouter{
+		newServe: CreateJSONRpcHandler,
+		fallback: CreateJSONRpcHandler(NewDefaultRpcHandlers(map[string]HandlingInfo{})),
+	}
+}
+
+func NewRpcRouterWithCodecs(codecs ...Codec) RpcRouter {
+	newServe := func(handlers RpcHandlers) func(w http.ResponseWriter, r *http.Request) {
+		return CreateRpcHandlerWithCodecs(handlers, codecs...)
+	}
+	return &rpcRouter{
+		codecs:   NewCodecs(NewJSONCodec(nil), codecs...),
+		newServe: newServe,
+		fallback: newServe(NewDefaultRpcHandlers(map[string]HandlingInfo{})),
+	}
+}
+
+func CreateJSONRpcRouterHandler(router RpcRouter) func(w http.ResponseWriter, r *http.Request) {
+	return router.ServeHTTP
+}
@@ -0,0 +1,62 @@
+package rpcsystem
+
+import (
+	"context"
+
+	"github.com/coldze/primitives/json_rpc"
+	"github.com/coldze/primitives/service"
+)
+
+const (
+	HealthMethod = json_rpc.DefaultHealthMethod
+	InfoMethod   = "system.info"
+)
+
+type HealthResult struct {
+	Liveness  *service.HealthReport `json:"liveness"`
+	Readiness *service.HealthReport `json:"readiness"`
+}
+
+type emptyParams struct{}
+
+func newEmptyParams() interface{} {
+	return &emptyParams{}
+}
+
+func NewHandlers(health service.Health) map[string]json_rpc.HandlingInfo {
+	if health == nil {
+		health = service.GetHealth()
+	}
+	return map[string]json_rpc.HandlingInfo{
+		HealthMethod: {
+			NewParams: newEmptyParams,
+			Handle: func(ctx context.Context, request *json_rpc.RequestInfo) (*json_rpc.ResponseInfo, json_rpc.ServerError) {
+				return &json_rpc.ResponseInfo{
+					Data: &HealthResult{
+						Liveness:  health.CheckLiveness(ctx),
+						Readiness: health.CheckReadiness(ctx),
+					},
+				}, nil
+			},
+		},
+		InfoMethod: {
+			NewParams: newEmptyParams,
+			Handle: func(ctx context.Context, request *json_rpc.RequestInfo) (*json_rpc.ResponseInfo, json_rpc.ServerError) {
+				return &json_rpc.ResponseInfo{
+					Data: service.GetInfo(),
+				}, nil
+			},
+		},
+	}
+}
+
+func WithHandlers(handlers map[string]json_rpc.HandlingInfo, health service.Health) map[string]json_rpc.HandlingInfo {
+	res := make(map[string]json_rpc.HandlingInfo, len(handlers)+2)
+	for k, v := range NewHandlers(health) {
+		res[k] = v
+	}
+	for k, v := range handlers {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,160 @@
+package rpctest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/coldze/primitives/json_rpc"
+)
+
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+type RawResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      string          `json:"id,omitempty"`
+	Err     *json_rpc.Error `json:"error,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+type CallResult struct {
+	t        TB
+	Status   int
+	Headers  http.Header
+	Body     []byte
+	Response *RawResponse
+}
+
+func newCallResult(t TB, resp *http.Response, body []byte) *CallResult {
+	t.Helper()
+	res := &CallResult{
+		t:       t,
+		Status:  resp.StatusCode,
+		Headers: resp.Header,
+		Body:    body,
+	}
+	response := &RawResponse{}
+	err := json.Unmarshal(body, response)
+	if err == nil {
+		res.Response = response
+	}
+	return res
+}
+
+func (r *CallResult) requireEnvelope() bool {
+	r.t.Helper()
+	if r.Response != nil {
+		return true
+	}
+	r.t.Errorf("Response is not a json-rpc envelope. Status: %v. Body: %s", r.Status, r.Body)
+	return false
+}
+
+func (r *CallResult) ExpectSuccess() *CallResult {
+	r.t.Helper()
+	if !r.requireEnvelope() {
+		return r
+	}
+	if r.Response.Err != nil {
+		r.t.Errorf("Expected success, got error. Status: %v. Error: %v: %v", r.Status, json_rpc.DescribeErrorCode(r.Response.Err.Code), r.Response.Err.Message)
+	}
+	return r
+}
+
+func (r *CallResult) ExpectStatus(status int) *CallResult {
+	r.t.Helper()
+	if r.Status != status {
+		r.t.Errorf("Expected HTTP status %v, got %v. Body: %s", status, r.Status, r.Body)
+	}
+	return r
+}
+
+func (r *CallResult) ExpectErrorCode(code int64) *CallResult {
+	r.t.Helper()
+	if !r.requireEnvelope() {
+		return r
+	}
+	if r.Response.Err == nil {
+		r.t.Errorf("Expected error %v, got success. Result: %s", json_rpc.DescribeErrorCode(code), r.Response.Result)
+		return r
+	}
+	if r.Response.Err.Code != code {
+		r.t.Errorf("Expected error %v, got %v. Message: %v", json_rpc.DescribeErrorCode(code), json_rpc.DescribeErrorCode(r.Response.Err.Code), r.Response.Err.Message)
+	}
+	return r
+}
+
+func (r *CallResult) ExpectModuleError(module int, errorCode int) *CallResult {
+	r.t.Helper()
+	return r.ExpectErrorCode(json_rpc.MakeErrorCode(module, errorCode))
+}
+
+func (r *CallResult) ExpectErrorMessage(message string) *CallResult {
+	r.t.Helper()
+	if !r.requireEnvelope() {
+		return r
+	}
+	if r.Response.Err == nil {
+		r.t.Errorf("Expected error with message '%v', got success.", message)
+		return r
+	}
+	if r.Response.Err.Message != message {
+		r.t.Errorf("Expected error message '%v', got '%v'.", message, r.Response.Err.Message)
+	}
+	return r
+}
+
+func (r *CallResult) ExpectHeader(name string, value string) *CallResult {
+	r.t.Helper()
+	values := r.Headers[http.CanonicalHeaderKey(name)]
+	for i := range values {
+		if values[i] == value {
+			return r
+		}
+	}
+	r.t.Errorf("Expected header '%v' with value '%v'. Got: %v", name, value, values)
+	return r
+}
+
+func (r *CallResult) ExpectHeaderPresent(name string) *CallResult {
+	r.t.Helper()
+	if len(r.Headers.Get(name)) <= 0 {
+		r.t.Errorf("Expected header '%v' to be present.", name)
+	}
+	return r
+}
+
+func (r *CallResult) DecodeResult(v interface{}) *CallResult {
+	r.t.Helper()
+	if !r.requireEnvelope() {
+		return r
+	}
+	err := json.Unmarshal(r.Response.Result, v)
+	if err != nil {
+		r.t.Errorf("Failed to decode result into %T. Result: %s. Error: %v", v, r.Response.Result, err)
+	}
+	return r
+}
+
+func (r *CallResult) ExpectResult(expected interface{}) *CallResult {
+	r.t.Helper()
+	if !r.requireEnvelope() {
+		return r
+	}
+	expectedData, err := json.Marshal(expected)
+	if err != nil {
+		r.t.Errorf("Failed to marshal expected result. Error: %v", err)
+		return r
+	}
+	var want, got interface{}
+	_ = json.Unmarshal(expectedData, &want)
+	_ = json.Unmarshal(r.Response.Result, &got)
+	if !reflect.DeepEqual(want, got) {
+		r.t.Errorf("Unexpected result. Expected: %s. Got: %s", expectedData, r.Response.Result)
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package rpctest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coldze/primitives/logs"
+)
+
+const (
+	LevelDebug   = "DEBUG"
+	LevelInfo    = "INFO"
+	LevelWarning = "WARNING"
+	LevelError   = "ERROR"
+	LevelFatal   = "FATAL"
+)
+
+type LogEntry struct {
+	Level   string
+	Message string
+}
+
+type CapturingLogger interface {
+	logs.Logger
+	GetEntries() []LogEntry
+	Contains(level string, substring string) bool
+	Reset()
+}
+
+type capturingLogger struct {
+	lock    sync.Mutex
+	entries []LogEntry
+}
+
+func (l *capturingLogger) add(level string, format string, args ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = append(l.entries, LogEntry{
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *capturingLogger) Debugf(format string, args ...interface{}) {
+	l.add(LevelDebug, format, args...)
+}
+
+func (l *capturingLogger) Infof(format string, args ...interface{}) {
+	l.add(LevelInfo, format, args...)
+}
+
+func (l *capturingLogger) Warningf(format string, args ...interface{}) {
+	l.add(LevelWarning, format, args...)
+}
+
+func (l *capturingLogger) Errorf(format string, args ...interface{}) {
+	l.add(LevelError, format, args...)
+}
+
+func (l *capturingLogger) Fatalf(format string, args ...interface{}) {
+	l.add(LevelFatal, format, args...)
+}
+
+func (l *capturingLogger) GetEntries() []LogEntry {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	res := make([]LogEntry, len(l.entries))
+	copy(res, l.entries)
+	return res
+}
+
+func (l *capturingLogger) Contains(level string, substring string) bool {
+	entries := l.GetEntries()
+	for i := range entries {
+		if entries[i].Level == level && strings.Contains(entries[i].Message, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *capturingLogger) Reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = nil
+}
+
+func NewCapturingLogger() CapturingLogger {
+	return &capturingLogger{}
+}
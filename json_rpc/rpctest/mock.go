@@ -0,0 +1,291 @@
+package rpctest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/json_rpc"
+)
+
+const (
+	mock_module        = 0x7e57
+	mock_module_name   = "rpctest"
+	mock_unexpected    = 0
+	mock_cancelled     = 1
+	unlimited_calls    = -1
+	default_call_count = 1
+)
+
+func init() {
+	cErr := json_rpc.RegisterErrorModule(mock_module, mock_module_name, map[int]string{
+		mock_unexpected: "UNEXPECTED_CALL",
+		mock_cancelled:  "CALL_CANCELLED",
+	})
+	if cErr != nil {
+		panic(cErr)
+	}
+}
+
+type ParamMatcher func(params json.RawMessage) bool
+
+func AnyParams() ParamMatcher {
+	return func(params json.RawMessage) bool {
+		return true
+	}
+}
+
+func ParamsEqual(expected interface{}) ParamMatcher {
+	expectedData, err := json.Marshal(expected)
+	return func(params json.RawMessage) bool {
+		if err != nil {
+			return false
+		}
+		var want, got interface{}
+		_ = json.Unmarshal(expectedData, &want)
+		if len(params) > 0 {
+			_ = json.Unmarshal(params, &got)
+		}
+		return reflect.DeepEqual(want, got)
+	}
+}
+
+func ParamsMatch(newParams func() interface{}, matches func(params interface{}) bool) ParamMatcher {
+	return func(params json.RawMessage) bool {
+		v := newParams()
+		err := json.Unmarshal(params, v)
+		if err != nil {
+			return false
+		}
+		return matches(v)
+	}
+}
+
+type Expectation struct {
+	lock     sync.Mutex
+	method   string
+	matches  ParamMatcher
+	min      int
+	max      int
+	calls    int
+	delay    time.Duration
+	result   interface{}
+	headers  http.Header
+	err      json_rpc.ServerError
+	panicVal interface{}
+}
+
+func (e *Expectation) WithParams(matches ParamMatcher) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.matches = matches
+	return e
+}
+
+func (e *Expectation) Times(n int) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.min = n
+	e.max = n
+	return e
+}
+
+func (e *Expectation) AnyTimes() *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.min = 0
+	e.max = unlimited_calls
+	return e
+}
+
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.delay = d
+	return e
+}
+
+func (e *Expectation) Returns(result interface{}) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.result = result
+	return e
+}
+
+func (e *Expectation) ReturnsHeaders(headers http.Header) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.headers = headers
+	return e
+}
+
+func (e *Expectation) ReturnsError(err json_rpc.ServerError) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.err = err
+	return e
+}
+
+func (e *Expectation) Panics(v interface{}) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.panicVal = v
+	return e
+}
+
+func (e *Expectation) tryConsume(method string, params json.RawMessage) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.method != method {
+		return false
+	}
+	if e.max != unlimited_calls && e.calls >= e.max {
+		return false
+	}
+	if !e.matches(params) {
+		return false
+	}
+	e.calls++
+	return true
+}
+
+func (e *Expectation) respond(ctx context.Context) (*json_rpc.ResponseInfo, json_rpc.ServerError) {
+	e.lock.Lock()
+	delay, result, headers, err, panicVal := e.delay, e.result, e.headers, e.err, e.panicVal
+	e.lock.Unlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, json_rpc.MakeErrorWithHttpStatus(mock_module, mock_cancelled, http.StatusGatewayTimeout, "Mock call cancelled.", ctx.Err())
+		}
+	}
+	if panicVal != nil {
+		panic(panicVal)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &json_rpc.ResponseInfo{
+		Headers: headers,
+		Data:    result,
+	}, nil
+}
+
+func (e *Expectation) unmet() (int, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.calls, e.calls < e.min
+}
+
+type MockServer struct {
+	*Server
+	t            TB
+	lock         sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+func (m *MockServer) Expect(method string) *Expectation {
+	e := &Expectation{
+		method:  method,
+		matches: AnyParams(),
+		min:     default_call_count,
+		max:     default_call_count,
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+func (m *MockServer) find(method string, params json.RawMessage) *Expectation {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i := range m.expectations {
+		if m.expectations[i].tryConsume(method, params) {
+			return m.expectations[i]
+		}
+	}
+	m.unexpected = append(m.unexpected, method+" "+string(params))
+	return nil
+}
+
+func (m *MockServer) handle(method string) json_rpc.RequestHandler {
+	return func(ctx context.Context, request *json_rpc.RequestInfo) (*json_rpc.ResponseInfo, json_rpc.ServerError) {
+		var params json.RawMessage
+		raw, ok := request.Data.(*json.RawMessage)
+		if ok && raw != nil {
+			params = *raw
+		}
+		e := m.find(method, params)
+		if e == nil {
+			return nil, json_rpc.MakeErrorWithHttpStatus(mock_module, mock_unexpected, http.StatusNotFound, "Unexpected call: "+method, errors.New("Params: "+string(params)))
+		}
+		return e.respond(ctx)
+	}
+}
+
+func (m *MockServer) GetHandler(name string) (json_rpc.HandlingInfo, bool) {
+	return json_rpc.HandlingInfo{
+		Handle: m.handle(name),
+		NewParams: func() interface{} {
+			return &json.RawMessage{}
+		},
+		ComposeContext: func(ctx context.Context, request *json_rpc.RequestBase, r *http.Request) (context.Context, json_rpc.ServerError) {
+			return ctx, nil
+		},
+		GetHeaders: func(ctx context.Context) http.Header {
+			return nil
+		},
+	}, true
+}
+
+func (m *MockServer) GetHeaders(ctx context.Context) http.Header {
+	return http.Header{}
+}
+
+func (m *MockServer) GetDecoder(data io.Reader) *json.Decoder {
+	return json.NewDecoder(data)
+}
+
+func (m *MockServer) NewContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return ctx, func() {}
+}
+
+func (m *MockServer) Verify() {
+	m.t.Helper()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i := range m.unexpected {
+		m.t.Errorf("Unexpected json-rpc call: %v", m.unexpected[i])
+	}
+	for i := range m.expectations {
+		e := m.expectations[i]
+		calls, unmet := e.unmet()
+		if unmet {
+			e.lock.Lock()
+			m.t.Errorf("Expectation for method '%v' not met. Expected at least %v calls, got %v.", e.method, e.min, calls)
+			e.lock.Unlock()
+		}
+	}
+}
+
+func (m *MockServer) Close() {
+	m.t.Helper()
+	m.Server.Close()
+	m.Verify()
+}
+
+func NewMockServer(t TB) *MockServer {
+	mock := &MockServer{
+		t: t,
+	}
+	mock.Server = NewServer(mock)
+	return mock
+}
@@ -0,0 +1,120 @@
+package rpctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/json_rpc"
+	"github.com/coldze/primitives/logs"
+	"github.com/google/uuid"
+)
+
+type BoundClient interface {
+	Call(method string, args json_rpc.RPCArguments, expectedResult json_rpc.ResponseResultFactory) (*json_rpc.UntypedResponse, custom_error.CustomError)
+}
+
+type boundClient struct {
+	client json_rpc.Client
+	url    string
+}
+
+func (c *boundClient) Call(method string, args json_rpc.RPCArguments, expectedResult json_rpc.ResponseResultFactory) (*json_rpc.UntypedResponse, custom_error.CustomError) {
+	return c.client.Call(c.url, method, args, expectedResult)
+}
+
+type loggingRpcHandlers struct {
+	json_rpc.RpcHandlers
+	logger logs.Logger
+}
+
+func (h *loggingRpcHandlers) NewContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := h.RpcHandlers.NewContext(ctx)
+	return logs.SetLogger(ctx, h.logger), cancel
+}
+
+type Server struct {
+	HTTP   *httptest.Server
+	URL    string
+	Client BoundClient
+	Logs   CapturingLogger
+}
+
+func (s *Server) Close() {
+	s.HTTP.Close()
+}
+
+func (s *Server) Do(t TB, method string, args json_rpc.RPCArguments) *CallResult {
+	t.Helper()
+	request := json_rpc.UntypedRequest{
+		RequestBase: json_rpc.RequestBase{
+			Version: json_rpc.JSON_RPC_VERSION,
+			ID:      uuid.New().String(),
+			Method:  method,
+		},
+		RequestParams: json_rpc.RequestParams{
+			Params: args.Data,
+		},
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request. Method: '%v'. Error: %v", method, err)
+	}
+	return s.DoRaw(t, args.Headers, args.Cookies, bytes.NewReader(data))
+}
+
+func (s *Server) DoRaw(t TB, headers http.Header, cookies []*http.Cookie, body io.Reader) *CallResult {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, s.URL, body)
+	if err != nil {
+		t.Fatalf("Failed to create request. Error: %v", err)
+	}
+	for k, vs := range headers {
+		for i := range vs {
+			req.Header.Add(k, vs[i])
+		}
+	}
+	if len(req.Header.Get("Content-Type")) <= 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := range cookies {
+		req.AddCookie(cookies[i])
+	}
+	resp, err := s.HTTP.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request. Error: %v", err)
+	}
+	defer resp.Body.Close()
+	respData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response. Error: %v", err)
+	}
+	return newCallResult(t, resp, respData)
+}
+
+func NewServer(handlers json_rpc.RpcHandlers) *Server {
+	logger := NewCapturingLogger()
+	handler := json_rpc.CreateJSONRpcHandler(&loggingRpcHandlers{
+		RpcHandlers: handlers,
+		logger:      logger,
+	})
+	httpServer := httptest.NewServer(http.HandlerFunc(handler))
+	return &Server{
+		HTTP: httpServer,
+		URL:  httpServer.URL,
+		Client: &boundClient{
+			client: json_rpc.NewClient(httpServer.Client()),
+			url:    httpServer.URL,
+		},
+		Logs: logger,
+	}
+}
+
+func NewServerFromMap(handlers map[string]json_rpc.HandlingInfo) *Server {
+	return NewServer(json_rpc.NewDefaultRpcHandlers(handlers))
+}
@@ -0,0 +1,196 @@
+package json_rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/coldze/primitives/logs"
+)
+
+const (
+	stream_buffer_size    = 32 * 1024
+	stream_flush_interval = 64
+)
+
+type ResultStream interface {
+	Next(ctx context.Context) (interface{}, bool, ServerError)
+	Close()
+}
+
+type StreamIterator func(ctx context.Context) (interface{}, bool, ServerError)
+
+type iteratorStream struct {
+	next  StreamIterator
+	close func()
+}
+
+func (s *iteratorStream) Next(ctx context.Context) (interface{}, bool, ServerError) {
+	return s.next(ctx)
+}
+
+func (s *iteratorStream) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+func NewIteratorStream(next StreamIterator, close func()) ResultStream {
+	return &iteratorStream{
+		next:  next,
+		close: close,
+	}
+}
+
+type channelStream struct {
+	items <-chan interface{}
+	errs  <-chan ServerError
+	close func()
+}
+
+func (s *channelStream) Next(ctx context.Context) (interface{}, bool, ServerError) {
+	for {
+		if s.items == nil && s.errs == nil {
+			return nil, false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, MakeError(json_rpc_module, 6, "Stream cancelled.", ctx.Err())
+		case err, ok := <-s.errs:
+			if !ok || s.items == nil {
+				s.errs = nil
+			}
+			if err != nil {
+				return nil, false, err
+			}
+		case item, ok := <-s.items:
+			if ok {
+				return item, true, nil
+			}
+			s.items = nil
+		}
+	}
+}
+
+func (s *channelStream) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+func NewChannelStream(items <-chan interface{}, errs <-chan ServerError, close func()) ResultStream {
+	return &channelStream{
+		items: items,
+		errs:  errs,
+		close: close,
+	}
+}
+
+func nextStreamItem(ctx context.Context, stream ResultStream) (item interface{}, ok bool, err ServerError) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		serverError, isServerError := v.(ServerError)
+		if isServerError {
+			item, ok, err = nil, false, serverError
+			return
+		}
+		item, ok, err = nil, false, MakeError(json_rpc_module, 6, "Stream failed.", fmt.Errorf("%v", v))
+	}()
+	return stream.Next(ctx)
+}
+
+func collectStream(ctx context.Context, stream ResultStream) []interface{} {
+	res := []interface{}{}
+	for {
+		item, ok, err := nextStreamItem(ctx, stream)
+		if err != nil {
+			panic(err)
+		}
+		if !ok {
+			return res
+		}
+		res = append(res, item)
+	}
+}
+
+type streamWriter struct {
+	buf     *bufio.Writer
+	flusher http.Flusher
+	err     error
+}
+
+func (s *streamWriter) write(data []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.buf.Write(data)
+}
+
+func (s *streamWriter) writeValue(v interface{}) {
+	if s.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.write(data)
+}
+
+func (s *streamWriter) flush() {
+	if s.err != nil {
+		return
+	}
+	s.err = s.buf.Flush()
+	if s.err == nil && s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+func writeStreamResponse(ctx context.Context, w http.ResponseWriter, rid string, stream ResultStream) {
+	item, ok, err := nextStreamItem(ctx, stream)
+	if err != nil {
+		panic(err)
+	}
+	flusher, _ := w.(http.Flusher)
+	out := &streamWriter{
+		buf:     bufio.NewWriterSize(w, stream_buffer_size),
+		flusher: flusher,
+	}
+	out.write([]byte(`{"jsonrpc":`))
+	out.writeValue(JSON_RPC_VERSION)
+	if len(rid) > 0 {
+		out.write([]byte(`,"id":`))
+		out.writeValue(rid)
+	}
+	out.write([]byte(`,"result":[`))
+	count := 0
+	for ok && out.err == nil {
+		if count > 0 {
+			out.write([]byte(","))
+		}
+		out.writeValue(item)
+		count++
+		if count%stream_flush_interval == 0 {
+			out.flush()
+		}
+		item, ok, err = nextStreamItem(ctx, stream)
+	}
+	out.write([]byte("]"))
+	if err != nil {
+		logs.GetLogger(ctx).Errorf("[Request-ID: %v] Stream failed after %v items: %v", rid, count, err.GetMessage())
+		out.write([]byte(`,"error":`))
+		out.writeValue(err.ToError())
+	}
+	out.write([]byte("}\n"))
+	out.flush()
+	if out.err != nil {
+		logs.GetLogger(ctx).Warningf("[Request-ID: %v] Failed to write stream response: %v", rid, out.err)
+	}
+}
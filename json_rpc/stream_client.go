@@ -0,0 +1,229 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+type ResultStreamReader interface {
+	GetID() string
+	Next() (interface{}, bool, custom_error.CustomError)
+	Close() error
+}
+
+type StreamingClient interface {
+	CallStream(url string, method string, args RPCArguments, newItem ResponseResultFactory) (ResultStreamReader, custom_error.CustomError)
+}
+
+type resultStreamReader struct {
+	body       io.ReadCloser
+	dec        *json.Decoder
+	newItem    ResponseResultFactory
+	id         string
+	httpStatus int
+	done       bool
+}
+
+func (r *resultStreamReader) GetID() string {
+	return r.id
+}
+
+func (r *resultStreamReader) readKey() (string, bool, error) {
+	token, err := r.dec.Token()
+	if err != nil {
+		return "", false, err
+	}
+	key, ok := token.(string)
+	if ok {
+		return key, true, nil
+	}
+	if token == json.Delim('}') {
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("unexpected token %v", token)
+}
+
+func (r *resultStreamReader) readHead() (bool, custom_error.CustomError) {
+	token, err := r.dec.Token()
+	if err != nil {
+		return false, custom_error.MakeErrorf("Failed to read stream response. Error: %v", err)
+	}
+	if token != json.Delim('{') {
+		return false, custom_error.MakeErrorf("Failed to read stream response. Unexpected token: %v", token)
+	}
+	for {
+		key, ok, err := r.readKey()
+		if err != nil {
+			return false, custom_error.MakeErrorf("Failed to read stream response. Error: %v", err)
+		}
+		if !ok {
+			return false, nil
+		}
+		switch key {
+		case "id":
+			err = r.dec.Decode(&r.id)
+		case "error":
+			return false, r.readError()
+		case "result":
+			token, err = r.dec.Token()
+			if err != nil {
+				return false, custom_error.MakeErrorf("Failed to read stream result. Error: %v", err)
+			}
+			if token == nil {
+				continue
+			}
+			if token != json.Delim('[') {
+				return false, custom_error.MakeErrorf("Result is not a stream. Unexpected token: %v", token)
+			}
+			return true, nil
+		default:
+			err = r.dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return false, custom_error.MakeErrorf("Failed to read stream response. Error: %v", err)
+		}
+	}
+}
+
+func (r *resultStreamReader) readError() custom_error.CustomError {
+	envelope := remoteErrorEnvelope{}
+	err := r.dec.Decode(&envelope)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to read stream error. Error: %v", err)
+	}
+	return newRemoteError(&envelope, r.httpStatus)
+}
+
+func (r *resultStreamReader) readTail() custom_error.CustomError {
+	for {
+		key, ok, err := r.readKey()
+		if err != nil {
+			return custom_error.MakeErrorf("Failed to read stream response. Error: %v", err)
+		}
+		if !ok {
+			return nil
+		}
+		if key == "error" {
+			return r.readError()
+		}
+		err = r.dec.Decode(&json.RawMessage{})
+		if err != nil {
+			return custom_error.MakeErrorf("Failed to read stream response. Error: %v", err)
+		}
+	}
+}
+
+func (r *resultStreamReader) Next() (interface{}, bool, custom_error.CustomError) {
+	if r.done {
+		return nil, false, nil
+	}
+	if r.dec.More() {
+		item := r.newItem()
+		err := r.dec.Decode(item)
+		if err != nil {
+			r.done = true
+			return nil, false, custom_error.MakeErrorf("Failed to decode stream item. Error: %v", err)
+		}
+		return item, true, nil
+	}
+	r.done = true
+	_, err := r.dec.Token()
+	if err != nil {
+		return nil, false, custom_error.MakeErrorf("Failed to read stream end. Error: %v", err)
+	}
+	cErr := r.readTail()
+	if cErr != nil {
+		return nil, false, cErr
+	}
+	return nil, false, nil
+}
+
+func (r *resultStreamReader) Close() error {
+	r.done = true
+	return r.body.Close()
+}
+
+type streamingClient struct {
+	httpClient *http.Client
+	rpcVersion string
+	getID      IDFactory
+}
+
+func (c *streamingClient) CallStream(url string, method string, args RPCArguments, newItem ResponseResultFactory) (ResultStreamReader, custom_error.CustomError) {
+	requestID := c.getID()
+	errorBuilder := custom_error.NewPrefixedErrorBuilder(fmt.Sprintf("json-rpc request ID: '%v'. Method: '%v'. URL: '%v'. ", requestID, method, url))
+	data, err := json.Marshal(UntypedRequest{
+		RequestBase{
+			Method:  method,
+			ID:      requestID,
+			Version: c.rpcVersion,
+		},
+		RequestParams{
+			Params: args.Data,
+		},
+	})
+	if err != nil {
+		return nil, errorBuilder.MakeErrorf("Failed to marshal request. Error: %v", err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, errorBuilder.MakeErrorf("Failed to create http-request. Error: %v", err)
+	}
+	if args.Headers != nil {
+		req.Header = args.Headers
+	}
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.Header.Set("Accept", ContentTypeJSON)
+	for i := range args.Cookies {
+		req.AddCookie(args.Cookies[i])
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, errorBuilder.MakeErrorf("Failed to send request. Error: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		respData, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errorBuilder.MakeErrorf("Failed to read response. HTTP status: %v. Error: %v", resp.StatusCode, err)
+		}
+		remoteErr, ok := parseRemoteError(respData, resp.StatusCode)
+		if ok {
+			return nil, remoteErr
+		}
+		return nil, errorBuilder.MakeErrorf("%v: %v. Body: %v", resp.StatusCode, resp.Status, describeBody(respData))
+	}
+	reader := &resultStreamReader{
+		body:       resp.Body,
+		dec:        json.NewDecoder(resp.Body),
+		newItem:    newItem,
+		httpStatus: resp.StatusCode,
+	}
+	ok, cErr := reader.readHead()
+	if cErr != nil {
+		resp.Body.Close()
+		return nil, cErr
+	}
+	if !ok {
+		reader.done = true
+	}
+	return reader, nil
+}
+
+func NewStreamingClient(httpClient *http.Client) StreamingClient {
+	return &streamingClient{
+		httpClient: httpClient,
+		rpcVersion: JSON_RPC_VERSION,
+		getID:      guidID,
+	}
+}
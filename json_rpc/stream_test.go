@@ -0,0 +1,88 @@
+package json_rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestChannelStreamEnd(t *testing.T) {
+	failure := MakeErrorWithHttpStatus(json_rpc_module, 6, http.StatusInternalServerError, "Producer failed.", errors.New("failure"))
+	cases := []struct {
+		name    string
+		noErrs  bool
+		finish  func(errs chan ServerError)
+		timeout time.Duration
+		err     bool
+	}{
+		{
+			name: "error reported after items are closed",
+			finish: func(errs chan ServerError) {
+				time.Sleep(20 * time.Millisecond)
+				errs <- failure
+			},
+			err: true,
+		},
+		{
+			name: "errors closed after items",
+			finish: func(errs chan ServerError) {
+				time.Sleep(20 * time.Millisecond)
+				close(errs)
+			},
+		},
+		{
+			name: "nil error after items",
+			finish: func(errs chan ServerError) {
+				errs <- nil
+			},
+		},
+		{
+			name:   "no error channel",
+			noErrs: true,
+		},
+		{
+			name:    "cancelled while waiting for errors",
+			finish:  func(errs chan ServerError) {},
+			timeout: 20 * time.Millisecond,
+			err:     true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			items := make(chan interface{}, 1)
+			errs := make(chan ServerError)
+			items <- 1
+			close(items)
+			var stream ResultStream
+			if c.noErrs {
+				stream = NewChannelStream(items, nil, nil)
+			} else {
+				stream = NewChannelStream(items, errs, nil)
+				go c.finish(errs)
+			}
+			ctx := context.Background()
+			if c.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.timeout)
+				defer cancel()
+			}
+			item, ok, err := stream.Next(ctx)
+			if err != nil || !ok || item != 1 {
+				t.Fatalf("Expected first item, got: %v %v %v", item, ok, err)
+			}
+			_, ok, err = stream.Next(ctx)
+			if ok || (err != nil) != c.err {
+				t.Fatalf("Unexpected end of stream: ok=%v err=%v", ok, err)
+			}
+			if c.err {
+				return
+			}
+			_, ok, err = stream.Next(ctx)
+			if ok || err != nil {
+				t.Fatalf("Stream must stay finished: ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package json_rpc
+
+const (
+	sweep_interval = 1024
+)
@@ -0,0 +1,331 @@
+package json_rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+	"github.com/coldze/primitives/logs"
+)
+
+const (
+	DefaultVersionHeader = "X-Rpc-Version"
+	version_separator    = "@"
+)
+
+type Deprecation struct {
+	Since   time.Time
+	Sunset  time.Time
+	Link    string
+	Message string
+}
+
+func (d *Deprecation) apply(headers http.Header) {
+	if d.Since.IsZero() {
+		headers.Set("Deprecation", "true")
+	} else {
+		headers.Set("Deprecation", fmt.Sprintf("@%v", d.Since.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		headers.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if len(d.Link) > 0 {
+		headers.Add("Link", fmt.Sprintf("<%v>; rel=\"deprecation\"", d.Link))
+	}
+}
+
+type VersionedRpcHandlers interface {
+	RpcHandlers
+	Register(method string, version string, handler HandlingInfo) custom_error.CustomError
+	SetDefaultVersion(method string, version string) custom_error.CustomError
+	Alias(alias string, target string) custom_error.CustomError
+	Deprecate(name string, deprecation Deprecation)
+}
+
+type versionedMethod struct {
+	versions       map[string]HandlingInfo
+	defaultVersion string
+}
+
+type selectedVersionKey struct{}
+
+type selectedVersion struct {
+	version string
+	handler HandlingInfo
+}
+
+type versionedRpcHandlers struct {
+	RpcHandlers
+	versionHeader string
+	lock          sync.RWMutex
+	methods       map[string]*versionedMethod
+	aliases       map[string]string
+	deprecations  map[string]Deprecation
+}
+
+func splitVersion(name string) (string, string) {
+	idx := strings.LastIndex(name, version_separator)
+	if idx < 0 {
+		return name, ""
+	}
+	return name[:idx], name[idx+len(version_separator):]
+}
+
+func (v *versionedRpcHandlers) Register(method string, version string, handler HandlingInfo) custom_error.CustomError {
+	if len(version) <= 0 || strings.Contains(method, version_separator) {
+		return custom_error.MakeErrorf("Invalid method version. Method: '%v'. Version: '%v'.", method, version)
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	m, ok := v.methods[method]
+	if !ok {
+		m = &versionedMethod{
+			versions:       map[string]HandlingInfo{},
+			defaultVersion: version,
+		}
+		v.methods[method] = m
+	}
+	_, ok = m.versions[version]
+	if ok {
+		return custom_error.MakeErrorf("Method '%v' already has version '%v'.", method, version)
+	}
+	m.versions[version] = withDefaults(handler)
+	return nil
+}
+
+func (v *versionedRpcHandlers) SetDefaultVersion(method string, version string) custom_error.CustomError {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	m, ok := v.methods[method]
+	if !ok {
+		return custom_error.MakeErrorf("Method '%v' is not registered.", method)
+	}
+	_, ok = m.versions[version]
+	if !ok {
+		return custom_error.MakeErrorf("Method '%v' has no version '%v'.", method, version)
+	}
+	m.defaultVersion = version
+	return nil
+}
+
+func (v *versionedRpcHandlers) Alias(alias string, target string) custom_error.CustomError {
+	if alias == target {
+		return custom_error.MakeErrorf("Alias '%v' points to itself.", alias)
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	_, ok := v.aliases[alias]
+	if ok {
+		return custom_error.MakeErrorf("Alias '%v' is already registered.", alias)
+	}
+	v.aliases[alias] = target
+	return nil
+}
+
+func (v *versionedRpcHandlers) Deprecate(name string, deprecation Deprecation) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.deprecations[name] = deprecation
+}
+
+func (v *versionedRpcHandlers) findDeprecation(names ...string) (string, *Deprecation) {
+	for _, name := range names {
+		if len(name) <= 0 {
+			continue
+		}
+		d, ok := v.deprecations[name]
+		if ok {
+			return name, &d
+		}
+	}
+	return "", nil
+}
+
+func (v *versionedRpcHandlers) withDeprecation(requested string, method string, version string, handler HandlingInfo) HandlingInfo {
+	v.lock.RLock()
+	name, deprecation := v.findDeprecation(requested, method+version_separator+version, method)
+	v.lock.RUnlock()
+	if deprecation == nil {
+		return handler
+	}
+	handle := handler.Handle
+	handler.Handle = func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		logs.GetLogger(ctx).Warningf("Deprecated method called: '%v'. Sunset: %v. %v", name, deprecation.Sunset, deprecation.Message)
+		response, err := handle(ctx, request)
+		if err != nil || response == nil {
+			return response, err
+		}
+		if response.Headers == nil {
+			response.Headers = http.Header{}
+		}
+		deprecation.apply(response.Headers)
+		return response, nil
+	}
+	return handler
+}
+
+func (v *versionedRpcHandlers) resolveAlias(name string) string {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	seen := map[string]struct{}{}
+	for {
+		target, ok := v.aliases[name]
+		if !ok {
+			return name
+		}
+		_, loop := seen[name]
+		if loop {
+			return name
+		}
+		seen[name] = struct{}{}
+		name = target
+	}
+}
+
+func (v *versionedRpcHandlers) lookup(method string, version string) (HandlingInfo, string, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	m, ok := v.methods[method]
+	if !ok {
+		return HandlingInfo{}, "", false
+	}
+	if len(version) <= 0 {
+		version = m.defaultVersion
+	}
+	handler, ok := m.versions[version]
+	return handler, version, ok
+}
+
+func (v *versionedRpcHandlers) isVersioned(method string) bool {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	_, ok := v.methods[method]
+	return ok
+}
+
+func versionedCachePolicy(policy *CachePolicy) *CachePolicy {
+	if policy == nil {
+		return nil
+	}
+	res := *policy
+	key := policy.Key
+	if key == nil {
+		key = DefaultCacheKey
+	}
+	res.Key = func(ctx context.Context, method string, params interface{}) string {
+		selected, ok := ctx.Value(selectedVersionKey{}).(*selectedVersion)
+		if ok {
+			method = method + version_separator + selected.version
+		}
+		return key(ctx, method, params)
+	}
+	return &res
+}
+
+func (v *versionedRpcHandlers) selectByHeader(requested string, method string) HandlingInfo {
+	defaultHandler, _, _ := v.lookup(method, "")
+	return HandlingInfo{
+		Idempotent: defaultHandler.Idempotent,
+		Cache:      versionedCachePolicy(defaultHandler.Cache),
+		AllowGet:   defaultHandler.AllowGet,
+		NewParams: func() interface{} {
+			return &rawParams{}
+		},
+		ComposeContext: func(ctx context.Context, request *RequestBase, rawHttpRequest *http.Request) (context.Context, ServerError) {
+			requestedVersion := rawHttpRequest.Header.Get(v.versionHeader)
+			handler, version, ok := v.lookup(method, requestedVersion)
+			if !ok {
+				return ctx, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusBadRequest, "Unsupported method version: "+method+version_separator+requestedVersion, nil)
+			}
+			if !handler.AllowGet && (rawHttpRequest.Method == http.MethodGet || rawHttpRequest.Method == http.MethodHead) {
+				return ctx, MakeErrorWithHttpStatus(json_rpc_module, 3, http.StatusMethodNotAllowed, "Method is not available via GET: "+method+version_separator+version, nil)
+			}
+			handler = v.withDeprecation(requested, method, version, handler)
+			ctx, authErr := authorize(ctx, handler, request, rawHttpRequest)
+			if authErr != nil {
+				return ctx, authErr
+			}
+			ctx = context.WithValue(ctx, selectedVersionKey{}, &selectedVersion{
+				version: version,
+				handler: handler,
+			})
+			return handler.ComposeContext(ctx, request, rawHttpRequest)
+		},
+		GetHeaders: func(ctx context.Context) http.Header {
+			selected, ok := ctx.Value(selectedVersionKey{}).(*selectedVersion)
+			if !ok {
+				return nil
+			}
+			return selected.handler.GetHeaders(ctx)
+		},
+		Handle: func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+			selected, ok := ctx.Value(selectedVersionKey{}).(*selectedVersion)
+			if !ok {
+				return nil, MakeError(json_rpc_module, 3, "Method version is not selected.", errors.New(method))
+			}
+			params := selected.handler.NewParams()
+			raw, ok := request.Data.(*rawParams)
+			if ok && !raw.isEmpty() {
+				err := raw.decodeInto(ctx, params, v.GetDecoder)
+				if err != nil {
+					return nil, MakeError(json_rpc_module, 3, "Failed to prepare arguments for handler,", err)
+				}
+			}
+			return selected.handler.Handle(ctx, &RequestInfo{
+				Headers: request.Headers,
+				Cookies: request.Cookies,
+				Data:    params,
+			})
+		},
+	}
+}
+
+func (v *versionedRpcHandlers) resolveName(name string) (string, string) {
+	target := v.resolveAlias(name)
+	if target != name {
+		return splitVersion(target)
+	}
+	requestedMethod, version := splitVersion(name)
+	method, aliasVersion := splitVersion(v.resolveAlias(requestedMethod))
+	if len(version) <= 0 {
+		version = aliasVersion
+	}
+	return method, version
+}
+
+func (v *versionedRpcHandlers) GetHandler(name string) (HandlingInfo, bool) {
+	method, version := v.resolveName(name)
+	if len(version) > 0 {
+		handler, version, ok := v.lookup(method, version)
+		if !ok {
+			return HandlingInfo{}, false
+		}
+		return v.withDeprecation(name, method, version, handler), true
+	}
+	if v.isVersioned(method) {
+		return v.selectByHeader(name, method), true
+	}
+	handler, ok := v.RpcHandlers.GetHandler(method)
+	if !ok {
+		return handler, false
+	}
+	return v.withDeprecation(name, method, "", handler), true
+}
+
+func NewVersionedRpcHandlers(base RpcHandlers, versionHeader string) VersionedRpcHandlers {
+	if len(versionHeader) <= 0 {
+		versionHeader = DefaultVersionHeader
+	}
+	return &versionedRpcHandlers{
+		RpcHandlers:   base,
+		versionHeader: versionHeader,
+		methods:       map[string]*versionedMethod{},
+		aliases:       map[string]string{},
+		deprecations:  map[string]Deprecation{},
+	}
+}
@@ -0,0 +1,138 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type versionedTestParams struct {
+	Value int `json:"value"`
+}
+
+type versionedTestResult struct {
+	Version string `json:"version"`
+	Value   int    `json:"value"`
+	Calls   int32  `json:"calls"`
+}
+
+type versionedTestResponse struct {
+	Result *versionedTestResult `json:"result"`
+	Err    *remoteErrorEnvelope `json:"error"`
+}
+
+func newVersionedTestHandler(version string, calls *int32, info HandlingInfo) HandlingInfo {
+	info.NewParams = func() interface{} {
+		return &versionedTestParams{}
+	}
+	info.Handle = func(ctx context.Context, request *RequestInfo) (*ResponseInfo, ServerError) {
+		params := request.Data.(*versionedTestParams)
+		return &ResponseInfo{
+			Data: &versionedTestResult{
+				Version: version,
+				Value:   params.Value,
+				Calls:   atomic.AddInt32(calls, 1),
+			},
+		}, nil
+	}
+	return info
+}
+
+func newVersionedTestServer(t *testing.T, calls *int32) *httptest.Server {
+	versioned := NewVersionedRpcHandlers(NewDefaultRpcHandlers(map[string]HandlingInfo{}), "")
+	policy := &CachePolicy{
+		TTL:    time.Minute,
+		Shared: true,
+	}
+	for _, version := range []string{"1", "2"} {
+		cErr := versioned.Register("get", version, newVersionedTestHandler(version, calls, HandlingInfo{Cache: policy}))
+		if cErr != nil {
+			t.Fatalf("Failed to register get@%v. Error: %v", version, cErr)
+		}
+		cErr = versioned.Register("create", version, newVersionedTestHandler(version, calls, HandlingInfo{Idempotent: true}))
+		if cErr != nil {
+			t.Fatalf("Failed to register create@%v. Error: %v", version, cErr)
+		}
+	}
+	handlers := NewCachingRpcHandlers(NewIdempotentRpcHandlers(versioned, NewMemoryIdempotencyStore(), time.Minute), nil)
+	return httptest.NewServer(http.HandlerFunc(CreateJSONRpcHandler(handlers)))
+}
+
+func callVersioned(t *testing.T, url string, method string, version string, idempotencyKey string, value int) (int, *versionedTestResponse) {
+	body := `{"jsonrpc":"2.0","id":"1","method":"` + method + `","params":{"value":` + strconv.Itoa(value) + `}}`
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request. Error: %v", err)
+	}
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.Header.Set(DefaultVersionHeader, version)
+	if len(idempotencyKey) > 0 {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed. Error: %v", err)
+	}
+	defer resp.Body.Close()
+	res := &versionedTestResponse{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		t.Fatalf("Failed to decode response. Error: %v", err)
+	}
+	return resp.StatusCode, res
+}
+
+func TestVersionSelectedByHeaderIsCachedPerVersionAndParams(t *testing.T) {
+	var calls int32
+	server := newVersionedTestServer(t, &calls)
+	defer server.Close()
+
+	cases := []struct {
+		version string
+		value   int
+		calls   int32
+	}{
+		{version: "1", value: 1, calls: 1},
+		{version: "1", value: 1, calls: 1},
+		{version: "2", value: 1, calls: 2},
+		{version: "1", value: 2, calls: 3},
+		{version: "2", value: 1, calls: 2},
+	}
+	for i, c := range cases {
+		status, response := callVersioned(t, server.URL, "get", c.version, "", c.value)
+		if status != http.StatusOK || response.Result == nil {
+			t.Fatalf("Call %v failed. Status: %v. Error: %+v", i, status, response.Err)
+		}
+		if response.Result.Version != c.version || response.Result.Value != c.value || response.Result.Calls != c.calls {
+			t.Fatalf("Call %v returned unexpected result: %+v", i, response.Result)
+		}
+	}
+}
+
+func TestVersionSelectedByHeaderIsIdempotent(t *testing.T) {
+	var calls int32
+	server := newVersionedTestServer(t, &calls)
+	defer server.Close()
+
+	status, first := callVersioned(t, server.URL, "create", "2", "key-1", 1)
+	if status != http.StatusOK || first.Result == nil {
+		t.Fatalf("First call failed. Status: %v. Error: %+v", status, first.Err)
+	}
+	status, replayed := callVersioned(t, server.URL, "create", "2", "key-1", 1)
+	if status != http.StatusOK || replayed.Result == nil || replayed.Result.Calls != first.Result.Calls {
+		t.Fatalf("Expected replayed result. Status: %v. Result: %+v", status, replayed.Result)
+	}
+	status, conflict := callVersioned(t, server.URL, "create", "2", "key-1", 2)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected conflict for different params. Status: %v. Result: %+v", status, conflict.Result)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected handler to run once, ran %v times.", calls)
+	}
+}
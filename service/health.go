@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+	InfoPath    = "/info"
+
+	default_check_timeout = 5 * time.Second
+)
+
+type HealthCheck func(ctx context.Context) custom_error.CustomError
+
+type CheckResult struct {
+	Name     string `json:"name"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+func (r *HealthReport) IsHealthy() bool {
+	return r.Status == StatusOK
+}
+
+type Info struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Environment string    `json:"environment"`
+	StartedAt   time.Time `json:"started_at"`
+	Uptime      string    `json:"uptime"`
+}
+
+type Health interface {
+	AddLivenessCheck(name string, timeout time.Duration, check HealthCheck)
+	AddReadinessCheck(name string, timeout time.Duration, check HealthCheck)
+	CheckLiveness(ctx context.Context) *HealthReport
+	CheckReadiness(ctx context.Context) *HealthReport
+	SetShuttingDown()
+	IsShuttingDown() bool
+}
+
+type namedCheck struct {
+	name    string
+	timeout time.Duration
+	check   HealthCheck
+}
+
+type health struct {
+	lock         sync.RWMutex
+	liveness     map[string]*namedCheck
+	readiness    map[string]*namedCheck
+	shuttingDown bool
+}
+
+func addCheck(checks map[string]*namedCheck, name string, timeout time.Duration, check HealthCheck) {
+	if timeout <= 0 {
+		timeout = default_check_timeout
+	}
+	checks[name] = &namedCheck{
+		name:    name,
+		timeout: timeout,
+		check:   check,
+	}
+}
+
+func (h *health) AddLivenessCheck(name string, timeout time.Duration, check HealthCheck) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	addCheck(h.liveness, name, timeout, check)
+}
+
+func (h *health) AddReadinessCheck(name string, timeout time.Duration, check HealthCheck) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	addCheck(h.readiness, name, timeout, check)
+}
+
+func (h *health) snapshot(checks map[string]*namedCheck) []*namedCheck {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	res := make([]*namedCheck, 0, len(checks))
+	for _, v := range checks {
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+func safeRunCheck(ctx context.Context, check HealthCheck) (res custom_error.CustomError) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		res = custom_error.MakeErrorf("Check panicked: %v", r)
+	}()
+	return check(ctx)
+}
+
+func runCheck(ctx context.Context, check *namedCheck) CheckResult {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, check.timeout)
+	defer cancel()
+	done := make(chan custom_error.CustomError, 1)
+	go func() {
+		done <- safeRunCheck(ctx, check.check)
+	}()
+	var err custom_error.CustomError
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = custom_error.MakeErrorf("Check timed out after %v.", check.timeout)
+	}
+	res := CheckResult{
+		Name:     check.name,
+		Healthy:  err == nil,
+		Duration: time.Since(started).String(),
+	}
+	if err != nil {
+		res.Error = fmt.Sprint(err.GetError())
+	}
+	return res
+}
+
+func runChecks(ctx context.Context, checks []*namedCheck) *HealthReport {
+	report := &HealthReport{
+		Status: StatusOK,
+		Checks: make([]CheckResult, len(checks)),
+	}
+	wg := sync.WaitGroup{}
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+	for i := range report.Checks {
+		if !report.Checks[i].Healthy {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+func (h *health) CheckLiveness(ctx context.Context) *HealthReport {
+	return runChecks(ctx, h.snapshot(h.liveness))
+}
+
+func (h *health) CheckReadiness(ctx context.Context) *HealthReport {
+	if h.IsShuttingDown() {
+		return &HealthReport{
+			Status: StatusShuttingDown,
+		}
+	}
+	return runChecks(ctx, h.snapshot(h.readiness))
+}
+
+func (h *health) SetShuttingDown() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.shuttingDown = true
+}
+
+func (h *health) IsShuttingDown() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.shuttingDown
+}
+
+func NewHealth() Health {
+	return &health{
+		liveness:  map[string]*namedCheck{},
+		readiness: map[string]*namedCheck{},
+	}
+}
+
+var (
+	defaultHealth = NewHealth()
+	startedAt     = time.Now()
+)
+
+func GetHealth() Health {
+	return defaultHealth
+}
+
+func GetInfo() *Info {
+	return &Info{
+		Name:        GetServiceName(),
+		Version:     GetVersion(),
+		Environment: GetEnvironment(),
+		StartedAt:   startedAt,
+		Uptime:      time.Since(startedAt).Truncate(time.Second).String(),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func reportStatus(report *HealthReport) int {
+	if report.IsHealthy() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+func NewAdminMux(h Health) *http.ServeMux {
+	if h == nil {
+		h = defaultHealth
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, r *http.Request) {
+		report := h.CheckLiveness(r.Context())
+		writeJSON(w, reportStatus(report), report)
+	})
+	mux.HandleFunc(ReadyzPath, func(w http.ResponseWriter, r *http.Request) {
+		report := h.CheckReadiness(r.Context())
+		writeJSON(w, reportStatus(report), report)
+	})
+	mux.HandleFunc(InfoPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, GetInfo())
+	})
+	return mux
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+const (
+	default_component_timeout = 30 * time.Second
+)
+
+type Component interface {
+	Start(ctx context.Context) custom_error.CustomError
+	Stop(ctx context.Context) custom_error.CustomError
+}
+
+type FailingComponent interface {
+	Component
+	Failures() <-chan custom_error.CustomError
+}
+
+type ComponentFunc func(ctx context.Context) custom_error.CustomError
+
+type componentFuncs struct {
+	start ComponentFunc
+	stop  ComponentFunc
+}
+
+func (c *componentFuncs) Start(ctx context.Context) custom_error.CustomError {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c *componentFuncs) Stop(ctx context.Context) custom_error.CustomError {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+func NewComponent(start ComponentFunc, stop ComponentFunc) Component {
+	return &componentFuncs{
+		start: start,
+		stop:  stop,
+	}
+}
+
+type ComponentOptions struct {
+	DependsOn    []string
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+}
+
+type ExitCoder interface {
+	GetExitCode() int
+}
+
+type exitCodeError struct {
+	custom_error.CustomError
+	code int
+}
+
+func (e *exitCodeError) GetExitCode() int {
+	return e.code
+}
+
+func WithExitCode(err custom_error.CustomError, code int) custom_error.CustomError {
+	return &exitCodeError{
+		CustomError: err,
+		code:        code,
+	}
+}
+
+type aggregatedError struct {
+	custom_error.CustomError
+	errs []custom_error.CustomError
+}
+
+func (e *aggregatedError) GetErrors() []custom_error.CustomError {
+	return e.errs
+}
+
+func (e *aggregatedError) String() string {
+	res := e.CustomError.String()
+	for i := range e.errs {
+		res += e.errs[i].String()
+	}
+	return res
+}
+
+func (e *aggregatedError) Error() string {
+	return e.String()
+}
+
+func (e *aggregatedError) GetExitCode() int {
+	code := 0
+	for i := range e.errs {
+		c := ExitCodeFromError(e.errs[i])
+		if c > code {
+			code = c
+		}
+	}
+	return code
+}
+
+func aggregateErrors(message string, errs []custom_error.CustomError) custom_error.CustomError {
+	if len(errs) <= 0 {
+		return nil
+	}
+	return &aggregatedError{
+		CustomError: custom_error.MakeErrorf("%v Errors: %v", message, len(errs)),
+		errs:        errs,
+	}
+}
+
+func ExitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		coder, ok := e.(ExitCoder)
+		if !ok {
+			continue
+		}
+		code := coder.GetExitCode()
+		if code > 0 {
+			return code
+		}
+	}
+	return 1
+}
+
+type managedComponent struct {
+	name      string
+	component Component
+	options   ComponentOptions
+}
+
+type Lifecycle interface {
+	Add(name string, component Component, options ComponentOptions) custom_error.CustomError
+	Start(ctx context.Context) custom_error.CustomError
+	Stop(ctx context.Context) custom_error.CustomError
+	Failures() <-chan custom_error.CustomError
+	Main(stopping <-chan struct{}) int
+	MainContext(ctx context.Context) int
+}
+
+type lifecycle struct {
+	lock       sync.Mutex
+	components map[string]*managedComponent
+	order      []string
+	started    []*managedComponent
+	failures   chan custom_error.CustomError
+}
+
+func (l *lifecycle) Add(name string, component Component, options ComponentOptions) custom_error.CustomError {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, ok := l.components[name]
+	if ok {
+		return custom_error.MakeErrorf("Component '%v' is already registered.", name)
+	}
+	if options.StartTimeout <= 0 {
+		options.StartTimeout = default_component_timeout
+	}
+	if options.StopTimeout <= 0 {
+		options.StopTimeout = default_component_timeout
+	}
+	l.components[name] = &managedComponent{
+		name:      name,
+		component: component,
+		options:   options,
+	}
+	l.order = append(l.order, name)
+	return nil
+}
+
+func (l *lifecycle) resolveOrder() ([]*managedComponent, custom_error.CustomError) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	res := make([]*managedComponent, 0, len(l.components))
+	var visit func(name string, path []string) custom_error.CustomError
+	visit = func(name string, path []string) custom_error.CustomError {
+		c, ok := l.components[name]
+		if !ok {
+			return custom_error.MakeErrorf("Unknown component '%v'. Required by: %v", name, path)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return custom_error.MakeErrorf("Dependency cycle detected: %v -> %v", path, name)
+		}
+		state[name] = visiting
+		for _, dep := range c.options.DependsOn {
+			err := visit(dep, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		res = append(res, c)
+		return nil
+	}
+	for _, name := range l.order {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (l *lifecycle) watch(c *managedComponent) {
+	failing, ok := c.component.(FailingComponent)
+	if !ok {
+		return
+	}
+	go func() {
+		for err := range failing.Failures() {
+			if err == nil {
+				continue
+			}
+			select {
+			case l.failures <- custom_error.WrapErrorf(err, "Component '%v' failed.", c.name):
+			default:
+			}
+		}
+	}()
+}
+
+func runWithTimeout(parent context.Context, timeout time.Duration, name string, action string, run ComponentFunc) (res custom_error.CustomError) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	done := make(chan custom_error.CustomError, 1)
+	go func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			done <- custom_error.MakeErrorf("Component '%v' panicked on %v: %v", name, action, r)
+		}()
+		done <- run(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return custom_error.WrapErrorf(err, "Failed to %v component '%v'.", action, name)
+		}
+		return nil
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return custom_error.MakeErrorf("Failed to %v component '%v'. Cancelled. Error: %v", action, name, parent.Err())
+		}
+		return custom_error.MakeErrorf("Failed to %v component '%v'. Timeout: %v. Error: %v", action, name, timeout, ctx.Err())
+	}
+}
+
+func (l *lifecycle) Start(ctx context.Context) custom_error.CustomError {
+	l.lock.Lock()
+	ordered, err := l.resolveOrder()
+	l.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, c := range ordered {
+		log.Printf("Starting component '%v'.", c.name)
+		err = runWithTimeout(ctx, c.options.StartTimeout, c.name, "start", c.component.Start)
+		if err != nil {
+			errs := []custom_error.CustomError{err}
+			stopErr := l.Stop(context.Background())
+			if stopErr != nil {
+				errs = append(errs, stopErr)
+			}
+			return aggregateErrors("Failed to start components.", errs)
+		}
+		l.lock.Lock()
+		l.started = append(l.started, c)
+		l.lock.Unlock()
+		l.watch(c)
+	}
+	return nil
+}
+
+func (l *lifecycle) Stop(ctx context.Context) custom_error.CustomError {
+	l.lock.Lock()
+	started := l.started
+	l.started = nil
+	l.lock.Unlock()
+	errs := []custom_error.CustomError{}
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		log.Printf("Stopping component '%v'.", c.name)
+		err := runWithTimeout(ctx, c.options.StopTimeout, c.name, "stop", c.component.Stop)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return aggregateErrors("Failed to stop components.", errs)
+}
+
+func (l *lifecycle) Failures() <-chan custom_error.CustomError {
+	return l.failures
+}
+
+func (l *lifecycle) Main(stopping <-chan struct{}) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return l.MainContext(ctx)
+}
+
+func (l *lifecycle) MainContext(ctx context.Context) int {
+	err := l.Start(ctx)
+	if err != nil {
+		log.Printf("Failed to start. Error: %v", err)
+		return ExitCodeFromError(err)
+	}
+	errs := []custom_error.CustomError{}
+	select {
+	case <-ctx.Done():
+	case failure := <-l.failures:
+		log.Printf("Component failure. Stopping. Error: %v", failure)
+		errs = append(errs, failure)
+	}
+	err = l.Stop(context.Background())
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = aggregateErrors("Components failed.", errs)
+	if err != nil {
+		log.Printf("Stopped with errors: %v", err)
+	}
+	return ExitCodeFromError(err)
+}
+
+func NewLifecycle() Lifecycle {
+	return &lifecycle{
+		components: map[string]*managedComponent{},
+		failures:   make(chan custom_error.CustomError, 1),
+	}
+}
+
+func RunComponents(timeout time.Duration, l Lifecycle) {
+	Run(timeout, l.Main)
+}
+
+func RunComponentsContext(ctx context.Context, timeout time.Duration, l Lifecycle, signals <-chan os.Signal, health Health) (int, error) {
+	return RunContext(ctx, timeout, l.MainContext, signals, health)
+}
+
+type httpServerComponent struct {
+	lock     sync.Mutex
+	server   *http.Server
+	failures chan custom_error.CustomError
+}
+
+func (c *httpServerComponent) Start(ctx context.Context) custom_error.CustomError {
+	addr := c.server.Addr
+	if len(addr) <= 0 {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to listen on '%v'. Error: %v", addr, err)
+	}
+	failures := make(chan custom_error.CustomError, 1)
+	c.lock.Lock()
+	c.failures = failures
+	c.lock.Unlock()
+	go func() {
+		err := c.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			failures <- custom_error.MakeErrorf("HTTP server on '%v' failed. Error: %v", addr, err)
+		}
+		close(failures)
+	}()
+	return nil
+}
+
+func (c *httpServerComponent) Stop(ctx context.Context) custom_error.CustomError {
+	err := c.server.Shutdown(ctx)
+	if err != nil {
+		return custom_error.MakeErrorf("Failed to shutdown HTTP server. Error: %v", err)
+	}
+	return nil
+}
+
+func (c *httpServerComponent) Failures() <-chan custom_error.CustomError {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.failures
+}
+
+func NewHTTPServerComponent(server *http.Server) Component {
+	return &httpServerComponent{
+		server:   server,
+		failures: make(chan custom_error.CustomError, 1),
+	}
+}
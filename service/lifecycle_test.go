@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coldze/primitives/custom_error"
+)
+
+func TestLifecycleStartRollbackIgnoresStartContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stopErr error
+	stopped := false
+	l := NewLifecycle()
+	_ = l.Add("db", NewComponent(nil, func(ctx context.Context) custom_error.CustomError {
+		stopped = true
+		stopErr = ctx.Err()
+		return nil
+	}), ComponentOptions{})
+	_ = l.Add("api", NewComponent(func(ctx context.Context) custom_error.CustomError {
+		cancel()
+		return custom_error.MakeErrorf("failed to bind")
+	}, nil), ComponentOptions{DependsOn: []string{"db"}})
+
+	err := l.Start(ctx)
+	if err == nil {
+		t.Fatal("Expected start to fail.")
+	}
+	if !stopped {
+		t.Fatal("Started component was not rolled back.")
+	}
+	if stopErr != nil {
+		t.Fatalf("Rollback received a cancelled context: %v", stopErr)
+	}
+}
+
+func TestRunWithTimeoutReportsCancellation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(ctx context.Context) custom_error.CustomError {
+		<-release
+		return nil
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		timeout  time.Duration
+		expected string
+	}{
+		{name: "parent cancelled", ctx: cancelled, timeout: time.Minute, expected: "Cancelled"},
+		{name: "timeout", ctx: context.Background(), timeout: 10 * time.Millisecond, expected: "Timeout: 10ms"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := runWithTimeout(c.ctx, c.timeout, "component", "start", blocking)
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Fatalf("Expected error containing '%v', got: %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestHTTPServerComponentRestart(t *testing.T) {
+	component := NewHTTPServerComponent(&http.Server{Addr: "127.0.0.1:0"}).(FailingComponent)
+	for i := 0; i < 2; i++ {
+		err := component.Start(context.Background())
+		if err != nil {
+			t.Fatalf("Start %v failed. Error: %v", i, err)
+		}
+		failures := component.Failures()
+		err = component.Stop(context.Background())
+		if err != nil {
+			t.Fatalf("Stop %v failed. Error: %v", i, err)
+		}
+		select {
+		case _, ok := <-failures:
+			if ok {
+				t.Fatalf("Unexpected failure after start %v.", i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Failures channel was not closed after start %v.", i)
+		}
+	}
+}
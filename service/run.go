@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -11,46 +12,53 @@ import (
 )
 
 type MainFunc func(stopping <-chan struct{}) int
+type ContextMainFunc func(ctx context.Context) int
+
+type runResult struct {
+	code int
+	err  custom_error.CustomError
+}
 
 type gracefulShutdown struct {
 	WaitForShutdown  <-chan struct{}
-	ShutdownComplete chan<- int
-	ReturnCode       <-chan int
+	ShutdownComplete chan<- runResult
+	ReturnCode       <-chan runResult
 }
 
-func runGracefully(timeout time.Duration) *gracefulShutdown {
-	shutdown := make(chan struct{}, 10)
-	shutdownComplete := make(chan int, 10)
-	returnCode := make(chan int, 10)
-
-	gracefulStop := make(chan os.Signal, 10)
-	signal.Notify(gracefulStop, syscall.SIGTERM)
-	signal.Notify(gracefulStop, syscall.SIGINT)
+func runGracefully(ctx context.Context, timeout time.Duration, signals <-chan os.Signal, health Health) *gracefulShutdown {
+	shutdown := make(chan struct{})
+	shutdownComplete := make(chan runResult, 10)
+	returnCode := make(chan runResult, 10)
 
 	go func() {
-		defer close(shutdown)
-		defer close(gracefulStop)
-		exitCode := 0
 		select {
-		case exitCode = <-shutdownComplete:
+		case result := <-shutdownComplete:
 			{
-				log.Printf("Business logic completed. Exit code: %+v", exitCode)
-				returnCode <- exitCode
+				log.Printf("Business logic completed. Exit code: %+v", result.code)
+				returnCode <- result
 				return
 			}
 
-		case sig := <-gracefulStop:
+		case sig := <-signals:
 			log.Printf("Caught sig: %+v", sig)
-			shutdown <- struct{}{}
+		case <-ctx.Done():
+			log.Printf("Context done: %v", ctx.Err())
 		}
+		if health != nil {
+			health.SetShuttingDown()
+		}
+		close(shutdown)
 
 		select {
 		case <-time.After(timeout):
 			log.Print("Shutdown timeout occured. Terminating.")
-			returnCode <- 1
-		case exitCode := <-shutdownComplete:
+			returnCode <- runResult{
+				code: 1,
+				err:  custom_error.MakeErrorf("Shutdown timeout of %v exceeded.", timeout),
+			}
+		case result := <-shutdownComplete:
 			log.Print("Shutdown complete.")
-			returnCode <- exitCode
+			returnCode <- result
 		}
 	}()
 
@@ -61,37 +69,64 @@ func runGracefully(timeout time.Duration) *gracefulShutdown {
 	}
 }
 
-func safeRunAppLogic(appLogic MainFunc, stopChan <-chan struct{}) (res int) {
+func safeRunAppLogic(appLogic ContextMainFunc, ctx context.Context) (res runResult) {
 	defer func() {
 		r := recover()
 		if r == nil {
 			return
 		}
+		res.code = 1
 		customErr, ok := r.(custom_error.CustomError)
 		if ok {
 			log.Printf("mainFunc failed. Error: %v", customErr)
-			res = 1
+			res.err = customErr
 			return
 		}
 		err, ok := r.(error)
 		if ok {
 			log.Printf("mainFunc failed. Error: %v", err)
-			res = 1
+			res.err = custom_error.MakeError(err)
 			return
 		}
 		log.Printf("mainFunc failed. Unknown error: %+v. Type: %T", r, r)
-		res = 1
+		res.err = custom_error.MakeErrorf("Unknown error: %+v. Type: %T", r, r)
 	}()
-	return appLogic(stopChan)
+	return runResult{
+		code: appLogic(ctx),
+	}
 }
 
-func Run(timeout time.Duration, appLogic MainFunc) {
-
-	graceful := runGracefully(timeout)
+func RunContext(ctx context.Context, timeout time.Duration, appLogic ContextMainFunc, signals <-chan os.Signal, health Health) (int, error) {
+	appCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	graceful := runGracefully(appCtx, timeout, signals, health)
 	go func() {
-		graceful.ShutdownComplete <- safeRunAppLogic(appLogic, graceful.WaitForShutdown)
+		select {
+		case <-graceful.WaitForShutdown:
+			cancel()
+		case <-appCtx.Done():
+		}
+	}()
+	go func() {
+		graceful.ShutdownComplete <- safeRunAppLogic(appLogic, appCtx)
 	}()
-	exitCode := <-graceful.ReturnCode
+	result := <-graceful.ReturnCode
+	if result.err != nil {
+		return result.code, result.err
+	}
+	return result.code, nil
+}
+
+func Run(timeout time.Duration, appLogic MainFunc) {
+	signals := make(chan os.Signal, 10)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	exitCode, err := RunContext(context.Background(), timeout, func(ctx context.Context) int {
+		return appLogic(ctx.Done())
+	}, signals, GetHealth())
+	signal.Stop(signals)
+	if err != nil {
+		log.Printf("Application failed. Error: %v", err)
+	}
 	log.Printf("Exiting application. Code: %+v", exitCode)
 	os.Exit(exitCode)
 }
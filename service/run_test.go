@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	waitForShutdown := func(health Health) ContextMainFunc {
+		return func(ctx context.Context) int {
+			<-ctx.Done()
+			deadline := time.Now().Add(time.Second)
+			for !health.IsShuttingDown() {
+				if time.Now().After(deadline) {
+					return 2
+				}
+				time.Sleep(time.Millisecond)
+			}
+			return 0
+		}
+	}
+	cases := []struct {
+		name         string
+		appLogic     func(health Health) ContextMainFunc
+		cancel       bool
+		timeout      time.Duration
+		signal       os.Signal
+		code         int
+		err          bool
+		shuttingDown bool
+	}{
+		{name: "context cancelled", appLogic: waitForShutdown, cancel: true, code: 0, shuttingDown: true},
+		{name: "signal received", appLogic: waitForShutdown, signal: syscall.SIGTERM, code: 0, shuttingDown: true},
+		{
+			name: "exit code returned",
+			appLogic: func(health Health) ContextMainFunc {
+				return func(ctx context.Context) int {
+					return 3
+				}
+			},
+			code: 3,
+		},
+		{
+			name: "shutdown timeout",
+			appLogic: func(health Health) ContextMainFunc {
+				return func(ctx context.Context) int {
+					<-release
+					return 0
+				}
+			},
+			cancel:       true,
+			timeout:      50 * time.Millisecond,
+			code:         1,
+			err:          true,
+			shuttingDown: true,
+		},
+		{
+			name: "panic",
+			appLogic: func(health Health) ContextMainFunc {
+				return func(ctx context.Context) int {
+					panic("failed")
+				}
+			},
+			code: 1,
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			signals := make(chan os.Signal, 1)
+			health := NewHealth()
+			if c.cancel {
+				cancel()
+			}
+			if c.signal != nil {
+				signals <- c.signal
+			}
+			timeout := c.timeout
+			if timeout <= 0 {
+				timeout = 5 * time.Second
+			}
+			code, err := RunContext(ctx, timeout, c.appLogic(health), signals, health)
+			if code != c.code {
+				t.Fatalf("Expected exit code %v, got %v.", c.code, code)
+			}
+			if (err != nil) != c.err {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if health.IsShuttingDown() != c.shuttingDown {
+				t.Fatalf("Expected shutting down=%v.", c.shuttingDown)
+			}
+		})
+	}
+}
+
+func TestRunContextWithoutHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	code, err := RunContext(ctx, time.Second, func(ctx context.Context) int {
+		<-ctx.Done()
+		return 0
+	}, nil, nil)
+	if code != 0 || err != nil {
+		t.Fatalf("Unexpected result: %v %v", code, err)
+	}
+	if GetHealth().IsShuttingDown() {
+		t.Fatal("Global health must not be modified.")
+	}
+}